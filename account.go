@@ -4,12 +4,25 @@ import (
 	"crypto/ecdsa"
 	"encoding/hex"
 	"errors"
-	"log"
+	"fmt"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 
 	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	DefaultDerivationBasePath = "m/44'/60'/0'/0"
+	DefaultDerivationPath     = DefaultDerivationBasePath + "/0"
+)
+
+var (
+	ErrInvalidMnemonic       = errors.New("invalid mnemonic")
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
 )
 
 type TwentySixAccount struct {
@@ -18,6 +31,57 @@ type TwentySixAccount struct {
 	Address    string
 }
 
+func (account TwentySixAccount) GetAddress() string {
+	return account.Address
+}
+
+func (account TwentySixAccount) GetChain() MessageChain {
+	return EthereumChain
+}
+
+// Sign produces an EIP-191 personal signature of the payload, with the
+// recovery id shifted to 27/28 as expected by the network.
+func (account TwentySixAccount) Sign(payload []byte) (string, error) {
+	if account.PrivateKey == nil {
+		return "", errors.New("account has no private key")
+	}
+
+	signature, err := crypto.Sign(accounts.TextHash(payload), account.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return hexutil.Encode(signature), nil
+}
+
+func verifyEthereumSignature(sender string, signature string, payload []byte) error {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return err
+	}
+
+	if len(sig) != crypto.SignatureLength {
+		return errors.New("bad signature length")
+	}
+
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(accounts.TextHash(payload), sig)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(crypto.PubkeyToAddress(*publicKey).Hex(), sender) {
+		return &VerificationError{Check: ErrSenderMismatch}
+	}
+
+	return nil
+}
+
 func NewTwentySixAccountFromPrivateKey(privateKey string) (TwentySixAccount, error) {
 	var privateKeyBytes []byte
 	if privateKey[0:2] == "0x" {
@@ -41,7 +105,11 @@ func NewTwentySixAccountFromPrivateKey(privateKey string) (TwentySixAccount, err
 		return TwentySixAccount{}, errors.New("error casting private key to ECDSA")
 	}
 
-	publicKey := privateKeyEcdsa.Public()
+	return newTwentySixAccount(privateKeyEcdsa)
+}
+
+func newTwentySixAccount(privateKey *ecdsa.PrivateKey) (TwentySixAccount, error) {
+	publicKey := privateKey.Public()
 
 	publicKeyEcdsa, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
@@ -49,34 +117,98 @@ func NewTwentySixAccountFromPrivateKey(privateKey string) (TwentySixAccount, err
 	}
 
 	return TwentySixAccount{
-		PrivateKey: privateKeyEcdsa,
+		PrivateKey: privateKey,
 		PublicKey:  publicKeyEcdsa,
 		Address:    crypto.PubkeyToAddress(*publicKeyEcdsa).Hex(),
 	}, nil
 }
 
 func NewTwentySixAccountFromMnemonic(mnemonic string, derivationPath string) (TwentySixAccount, error) {
-	wallet, err := hdwallet.NewFromMnemonic(mnemonic)
+	return NewTwentySixAccountFromMnemonicWithPassphrase(mnemonic, "", derivationPath)
+}
+
+// NewTwentySixAccountFromMnemonicWithPassphrase derives a single account
+// from a BIP-39 mnemonic protected by an optional passphrase. An empty
+// derivation path defaults to DefaultDerivationPath.
+func NewTwentySixAccountFromMnemonicWithPassphrase(mnemonic string, passphrase string, derivationPath string) (TwentySixAccount, error) {
+	wallet, err := newHDWallet(mnemonic, passphrase)
 	if err != nil {
-		log.Fatal(err)
+		return TwentySixAccount{}, err
 	}
 
-	var dpath string
 	if len(derivationPath) == 0 {
-		dpath = "m/44'/60'/0'/0/0"
-	} else {
-		dpath = derivationPath
+		derivationPath = DefaultDerivationPath
 	}
 
-	path := hdwallet.MustParseDerivationPath(dpath)
-	account, err := wallet.Derive(path, true)
+	path, err := hdwallet.ParseDerivationPath(derivationPath)
 	if err != nil {
-		return TwentySixAccount{}, err
+		return TwentySixAccount{}, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
 	}
 
-	publicKey, err := wallet.PublicKey(account)
+	return deriveTwentySixAccount(wallet, path)
+}
+
+// NewTwentySixAccountsFromMnemonic derives count accounts from a BIP-39
+// mnemonic, using the indexes start to start+count-1 appended to basePath.
+// An empty base path defaults to DefaultDerivationBasePath.
+func NewTwentySixAccountsFromMnemonic(mnemonic string, passphrase string, basePath string, start uint32, count uint32) ([]TwentySixAccount, error) {
+	wallet, err := newHDWallet(mnemonic, passphrase)
 	if err != nil {
-		return TwentySixAccount{}, err
+		return nil, err
+	}
+
+	if len(basePath) == 0 {
+		basePath = DefaultDerivationBasePath
+	}
+
+	base, err := hdwallet.ParseDerivationPath(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
+	}
+
+	if uint64(start)+uint64(count) > 0x80000000 {
+		return nil, fmt.Errorf("%w: index out of non-hardened range", ErrInvalidDerivationPath)
+	}
+
+	result := make([]TwentySixAccount, 0, count)
+	for index := start; index < start+count; index++ {
+		path := make(accounts.DerivationPath, len(base), len(base)+1)
+		copy(path, base)
+		path = append(path, index)
+
+		account, err := deriveTwentySixAccount(wallet, path)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, account)
+	}
+
+	return result, nil
+}
+
+// GenerateMnemonic returns a new random BIP-39 mnemonic of 12, 15, 18, 21
+// or 24 words.
+func GenerateMnemonic(words int) (string, error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+	}
+
+	return hdwallet.NewMnemonic(words / 3 * 32)
+}
+
+func newHDWallet(mnemonic string, passphrase string) (*hdwallet.Wallet, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+
+	return hdwallet.NewFromMnemonic(mnemonic, passphrase)
+}
+
+func deriveTwentySixAccount(wallet *hdwallet.Wallet, path accounts.DerivationPath) (TwentySixAccount, error) {
+	account, err := wallet.Derive(path, false)
+	if err != nil {
+		return TwentySixAccount{}, fmt.Errorf("%w: %v", ErrInvalidDerivationPath, err)
 	}
 
 	privateKey, err := wallet.PrivateKey(account)
@@ -84,9 +216,5 @@ func NewTwentySixAccountFromMnemonic(mnemonic string, derivationPath string) (Tw
 		return TwentySixAccount{}, err
 	}
 
-	return TwentySixAccount{
-		PrivateKey: privateKey,
-		PublicKey:  publicKey,
-		Address:    crypto.PubkeyToAddress(*publicKey).Hex(),
-	}, nil
+	return newTwentySixAccount(privateKey)
 }
@@ -1,6 +1,8 @@
 package client
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -66,3 +68,70 @@ func TestAccountCreationFromMnemonic(t *testing.T) {
 		t.Fatalf(`Bad address key generated`)
 	}
 }
+
+func TestAccountsDerivationFromMnemonic(t *testing.T) {
+
+	mnemonic := "test test test test test test test test test test test junk"
+
+	accs, err := NewTwentySixAccountsFromMnemonic(mnemonic, "", "", 0, 3)
+	if err != nil {
+		t.Fatalf(`NewTwentySixAccountsFromMnemonic failed: %v`, err)
+	}
+
+	expected := []string{
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
+		"0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		"0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC",
+	}
+
+	if len(accs) != len(expected) {
+		t.Fatalf(`Bad number of accounts derived`)
+	}
+	for i := range expected {
+		if accs[i].Address != expected[i] {
+			t.Fatalf(`Bad address derived at index %d: %s`, i, accs[i].Address)
+		}
+	}
+
+	withPassphrase, err := NewTwentySixAccountFromMnemonicWithPassphrase(mnemonic, "secret", "")
+	if err != nil {
+		t.Fatalf(`NewTwentySixAccountFromMnemonicWithPassphrase failed: %v`, err)
+	}
+	if withPassphrase.Address == expected[0] {
+		t.Fatalf(`Passphrase was ignored`)
+	}
+}
+
+func TestAccountCreationFromInvalidMnemonic(t *testing.T) {
+
+	if _, err := NewTwentySixAccountFromMnemonic("test test test", ""); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Fatalf(`Invalid mnemonic not reported: %v`, err)
+	}
+
+	mnemonic := "test test test test test test test test test test test junk"
+	if _, err := NewTwentySixAccountFromMnemonic(mnemonic, "m/not/a/path"); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Fatalf(`Invalid derivation path not reported: %v`, err)
+	}
+}
+
+func TestGenerateMnemonic(t *testing.T) {
+
+	for _, words := range []int{12, 24} {
+		mnemonic, err := GenerateMnemonic(words)
+		if err != nil {
+			t.Fatalf(`GenerateMnemonic failed: %v`, err)
+		}
+
+		if len(strings.Fields(mnemonic)) != words {
+			t.Fatalf(`Bad number of words generated`)
+		}
+
+		if _, err := NewTwentySixAccountFromMnemonic(mnemonic, ""); err != nil {
+			t.Fatalf(`Generated mnemonic is unusable: %v`, err)
+		}
+	}
+
+	if _, err := GenerateMnemonic(13); err == nil {
+		t.Fatalf(`GenerateMnemonic accepted 13 words`)
+	}
+}
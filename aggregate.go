@@ -1,60 +1,82 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
 	"time"
 )
 
+var ErrAggregateNotFound = errors.New("aggregate not found")
+
 func (client *TwentySixClient) CreateAggregate(aggregate AggregateMessageContent) (Message, MessageResponse, error) {
+	return client.CreateAggregateWithContext(context.Background(), aggregate)
+}
+
+func (client *TwentySixClient) CreateAggregateWithContext(ctx context.Context, aggregate AggregateMessageContent) (Message, MessageResponse, error) {
 	now := float64(time.Now().UnixMilli()) / 1000
 
 	aggregateMessage := aggregate
 	aggregateMessage.Time = now
-	aggregateMessage.Address = client.account.Address
+	aggregateMessage.Address = client.Address()
 
-	message, res, err := client.SendMessage(InstanceMessageType, aggregateMessage, now)
+	message, res, _, err := client.publish(ctx, AggregateMessageType, aggregateMessage, now)
 	if err != nil {
 		return Message{}, MessageResponse{}, err
 	}
 
-	var createAggregateResponse MessageResponse
-	if err := json.Unmarshal(res, &createAggregateResponse); err != nil {
-		return Message{}, MessageResponse{}, err
+	return message, res, nil
+}
+
+// GetAggregate fetches the current value of an aggregate key of address and
+// decodes it into result.
+func (client *TwentySixClient) GetAggregate(address string, key string, result interface{}) error {
+	return client.GetAggregateWithContext(context.Background(), address, key, result)
+}
+
+func (client *TwentySixClient) GetAggregateWithContext(ctx context.Context, address string, key string, result interface{}) error {
+	path := "/api/v0/aggregates/" + url.PathEscape(address) + ".json?keys=" + url.QueryEscape(key)
+
+	response, err := client.doApi(ctx, "GET", path, nil, "")
+	if err != nil {
+		return err
+	}
+
+	resultBody, err := readResponse(response)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: %w", ErrAggregateNotFound, err)
+	}
+	if err != nil {
+		return err
 	}
 
-	return message, createAggregateResponse, nil
+	var aggregateResponse GetAggregateResponse
+	if err := json.Unmarshal(resultBody, &aggregateResponse); err != nil {
+		return err
+	}
+
+	content, ok := aggregateResponse.Data[key]
+	if !ok {
+		return ErrAggregateNotFound
+	}
+
+	return json.Unmarshal(content, result)
 }
 
 func (client *TwentySixClient) GetAggregateMessages(size uint64, page uint64) ([]Message, uint64, error) {
-	return client.GetMessages(size, page, []string{}, []string{client.account.Address}, []string{client.channel}, []MessageType{AggregateMessageType})
+	return client.GetAggregateMessagesWithContext(context.Background(), size, page)
+}
+
+func (client *TwentySixClient) GetAggregateMessagesWithContext(ctx context.Context, size uint64, page uint64) ([]Message, uint64, error) {
+	return client.GetMessagesWithContext(ctx, size, page, nil, []string{client.Address()}, []string{client.channel}, []MessageType{AggregateMessageType})
 }
 
 func (client *TwentySixClient) GetAggregateMessageByItemHash(hash string) (Message, error) {
-	var page uint64 = 1
-	var parsingEnded = false
-
-	for !parsingEnded {
-		volumes, remainingItems, err := client.GetProgramMessages(50, page)
-		if err != nil {
-			return Message{}, err
-		}
-
-		for i := 0; i < len(volumes); i++ {
-			var itemContent StoreMessageContent
-			json.Unmarshal([]byte(volumes[i].ItemContent), &itemContent)
-
-			if itemContent.ItemHash == hash {
-				return volumes[i], nil
-			}
-		}
-
-		if remainingItems > 0 {
-			page += 1
-		} else {
-			parsingEnded = true
-		}
-	}
+	return client.GetAggregateMessageByItemHashWithContext(context.Background(), hash)
+}
 
-	return Message{}, errors.New("aggregate message not found")
+func (client *TwentySixClient) GetAggregateMessageByItemHashWithContext(ctx context.Context, hash string) (Message, error) {
+	return client.findOwnMessage(ctx, AggregateMessageType, hash)
 }
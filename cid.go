@@ -0,0 +1,419 @@
+package client
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// IPFS content is addressed by the CID of a UnixFS file DAG built the way
+// `ipfs add` does by default: 256 KiB chunks and a balanced layout of at most
+// 174 links per node. CIDv0 DAGs have dag-pb leaves, CIDv1 DAGs raw leaves as
+// `ipfs add --cid-version=1` makes them.
+const (
+	ipfsChunkSize   = 256 * 1024
+	ipfsMaxLinks    = 174
+	unixfsFileType  = 2
+	multihashSha256 = 0x12
+	dagPbCodec      = 0x70
+	rawCodec        = 0x55
+
+	unixfsDirectoryType = 1
+	ipfsShardingSize    = 256 * 1024
+)
+
+// dagLink references a node: its binary CID, the multihash alone for CIDv0,
+// the size of the whole sub-DAG and the size of the file data below it.
+type dagLink struct {
+	name     string
+	hash     []byte
+	tsize    uint64
+	filesize uint64
+}
+
+// unixfsFileBuilder turns file chunks into the nodes of a balanced UnixFS
+// DAG as they come, keeping only the pending links of each level. A CIDv1
+// builder stores chunks as raw leaves.
+type unixfsFileBuilder struct {
+	cidVersion int
+	levels     [][]dagLink
+	emit       func(hash []byte, block []byte) error
+}
+
+func (builder *unixfsFileBuilder) addChunk(chunk []byte) error {
+	var link dagLink
+	var err error
+
+	if builder.cidVersion == 1 {
+		link, err = builder.store(chunk, nil, rawCodec)
+	} else {
+		link, err = builder.store(encodeDagNode(nil, encodeUnixfs(unixfsFileType, chunk, uint64(len(chunk)), nil)), nil, dagPbCodec)
+	}
+	if err != nil {
+		return err
+	}
+
+	link.filesize = uint64(len(chunk))
+
+	return builder.push(0, link)
+}
+
+func (builder *unixfsFileBuilder) push(level int, link dagLink) error {
+	if len(builder.levels) <= level {
+		builder.levels = append(builder.levels, nil)
+	}
+
+	builder.levels[level] = append(builder.levels[level], link)
+	if len(builder.levels[level]) < ipfsMaxLinks {
+		return nil
+	}
+
+	parent, err := builder.pack(builder.levels[level])
+	if err != nil {
+		return err
+	}
+
+	builder.levels[level] = nil
+
+	return builder.push(level+1, parent)
+}
+
+func (builder *unixfsFileBuilder) pack(links []dagLink) (dagLink, error) {
+	var filesize uint64
+	blocksizes := make([]uint64, len(links))
+	for i, link := range links {
+		filesize += link.filesize
+		blocksizes[i] = link.filesize
+	}
+
+	block := encodeDagNode(links, encodeUnixfs(unixfsFileType, nil, filesize, blocksizes))
+
+	link, err := builder.store(block, links, dagPbCodec)
+	link.filesize = filesize
+
+	return link, err
+}
+
+func (builder *unixfsFileBuilder) store(block []byte, links []dagLink, codec uint64) (dagLink, error) {
+	link := newDagLink(block, links)
+	if builder.cidVersion == 1 {
+		link.hash = cidV1(codec, link.hash)
+	}
+
+	if builder.emit != nil {
+		if err := builder.emit(link.hash, block); err != nil {
+			return dagLink{}, err
+		}
+	}
+
+	return link, nil
+}
+
+// newDagLink returns a link to the node encoded in block, whose children are
+// links.
+func newDagLink(block []byte, links []dagLink) dagLink {
+	hash := sha256.Sum256(block)
+	multihash := append([]byte{multihashSha256, sha256.Size}, hash[:]...)
+
+	tsize := uint64(len(block))
+	for _, link := range links {
+		tsize += link.tsize
+	}
+
+	return dagLink{hash: multihash, tsize: tsize}
+}
+
+// cidV1 returns the binary CIDv1 of the node with the given codec and
+// multihash.
+func cidV1(codec uint64, multihash []byte) []byte {
+	cid := binary.AppendUvarint([]byte{1}, codec)
+	return append(cid, multihash...)
+}
+
+// formatCID returns the text form IPFS gives to a binary CID: base58 for
+// CIDv0, base32 for CIDv1.
+func formatCID(cid []byte) string {
+	if len(cid) > 0 && cid[0] == 1 {
+		return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(cid))
+	}
+
+	return base58.Encode(cid)
+}
+
+// unixfsDirectory returns the link to the UnixFS directory node holding
+// links, which must be named and sorted by name. Directories large enough
+// for `ipfs add` to shard them are not supported.
+func unixfsDirectory(links []dagLink) (dagLink, error) {
+	estimated := 0
+	for _, link := range links {
+		estimated += len(link.name) + len(link.hash)
+	}
+
+	if estimated > ipfsShardingSize {
+		return dagLink{}, fmt.Errorf("directory of %d entries is too large to be stored without sharding", len(links))
+	}
+
+	block := encodeDagNode(links, encodeUnixfs(unixfsDirectoryType, nil, 0, nil))
+
+	return newDagLink(block, links), nil
+}
+
+// root packs the pending links of every level and returns the link to the
+// root node.
+func (builder *unixfsFileBuilder) root() (dagLink, error) {
+	if len(builder.levels) == 0 {
+		// An empty file is a single node without data.
+		if builder.cidVersion == 1 {
+			return builder.store(nil, nil, rawCodec)
+		}
+
+		block := encodeDagNode(nil, encodeUnixfs(unixfsFileType, nil, 0, nil))
+		return builder.store(block, nil, dagPbCodec)
+	}
+
+	for level := 0; ; level++ {
+		links := builder.levels[level]
+		if level == len(builder.levels)-1 && len(links) == 1 {
+			return links[0], nil
+		}
+
+		if len(links) == 0 {
+			continue
+		}
+
+		parent, err := builder.pack(links)
+		if err != nil {
+			return dagLink{}, err
+		}
+
+		builder.levels[level] = nil
+		if err := builder.push(level+1, parent); err != nil {
+			return dagLink{}, err
+		}
+	}
+}
+
+// unixfsFileWriter cuts what is written to it into chunks fed to a
+// unixfsFileBuilder.
+type unixfsFileWriter struct {
+	builder unixfsFileBuilder
+	chunk   []byte
+}
+
+func newUnixfsFileWriter(cidVersion int, emit func(hash []byte, block []byte) error) *unixfsFileWriter {
+	return &unixfsFileWriter{
+		builder: unixfsFileBuilder{cidVersion: cidVersion, emit: emit},
+		chunk:   make([]byte, 0, ipfsChunkSize),
+	}
+}
+
+func (writer *unixfsFileWriter) Write(data []byte) (int, error) {
+	written := 0
+
+	for len(data) > 0 {
+		n := copy(writer.chunk[len(writer.chunk):ipfsChunkSize], data)
+		writer.chunk = writer.chunk[:len(writer.chunk)+n]
+		data = data[n:]
+		written += n
+
+		if len(writer.chunk) == ipfsChunkSize {
+			if err := writer.builder.addChunk(writer.chunk); err != nil {
+				return written, err
+			}
+
+			writer.chunk = writer.chunk[:0]
+		}
+	}
+
+	return written, nil
+}
+
+// root adds the last partial chunk and returns the link to the root node.
+func (writer *unixfsFileWriter) root() (dagLink, error) {
+	if len(writer.chunk) > 0 {
+		if err := writer.builder.addChunk(writer.chunk); err != nil {
+			return dagLink{}, err
+		}
+
+		writer.chunk = writer.chunk[:0]
+	}
+
+	return writer.builder.root()
+}
+
+// Sum returns the CID of what was written.
+func (writer *unixfsFileWriter) Sum() (string, error) {
+	root, err := writer.root()
+	if err != nil {
+		return "", err
+	}
+
+	return formatCID(root.hash), nil
+}
+
+// ipfsFileCID returns the CIDv0 `ipfs add` gives to the content of reader.
+func ipfsFileCID(reader io.Reader) (string, error) {
+	writer := newUnixfsFileWriter(0, nil)
+	if _, err := io.Copy(writer, reader); err != nil {
+		return "", err
+	}
+
+	return writer.Sum()
+}
+
+// cidHasher computes the CIDs a file gets with both `ipfs add` defaults, for
+// CIDv0 and CIDv1, to check a CID of either version.
+type cidHasher struct {
+	writers  []*unixfsFileWriter
+	computed []string
+	err      error
+}
+
+// newCidHasher returns a hasher for the DAGs cid may address, or for both
+// when cid is empty.
+func newCidHasher(cid string) *cidHasher {
+	hasher := &cidHasher{}
+
+	codec, _, ok := parseCID(cid)
+	if cid == "" || !ok || strings.HasPrefix(cid, "Qm") || codec == dagPbCodec {
+		// A CIDv1 dag-pb root may be a CIDv0 DAG printed in base32.
+		hasher.writers = append(hasher.writers, newUnixfsFileWriter(0, nil))
+	}
+	if !strings.HasPrefix(cid, "Qm") {
+		hasher.writers = append(hasher.writers, newUnixfsFileWriter(1, nil))
+	}
+
+	return hasher
+}
+
+func (hasher *cidHasher) Write(data []byte) (int, error) {
+	for _, writer := range hasher.writers {
+		if _, err := writer.Write(data); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// sums returns the CIDs of what was written, once it was all written.
+func (hasher *cidHasher) sums() ([]string, error) {
+	if hasher.computed == nil && hasher.err == nil {
+		for _, writer := range hasher.writers {
+			sum, err := writer.Sum()
+			if err != nil {
+				hasher.err = err
+				break
+			}
+
+			hasher.computed = append(hasher.computed, sum)
+		}
+	}
+
+	return hasher.computed, hasher.err
+}
+
+// match returns the computed CID addressing the same node as cid, or the
+// first computed CID when none does.
+func (hasher *cidHasher) match(cid string) (string, bool, error) {
+	sums, err := hasher.sums()
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, sum := range sums {
+		if sameCID(sum, cid) {
+			return sum, true, nil
+		}
+	}
+
+	return sums[0], false, nil
+}
+
+// sameCID reports whether both CIDs address the same node, whatever their
+// version: CIDv0 in base58 or CIDv1 in base32 as IPFS prints them.
+func sameCID(a string, b string) bool {
+	codecA, multihashA, okA := parseCID(a)
+	codecB, multihashB, okB := parseCID(b)
+
+	return okA && okB && codecA == codecB && bytes.Equal(multihashA, multihashB)
+}
+
+// parseCID returns the codec and the multihash of a dag-pb or raw CID.
+func parseCID(cid string) (uint64, []byte, bool) {
+	if strings.HasPrefix(cid, "Qm") {
+		multihash := base58.Decode(cid)
+		return dagPbCodec, multihash, len(multihash) > 0
+	}
+
+	if !strings.HasPrefix(cid, "b") {
+		return 0, nil, false
+	}
+
+	decoded, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(cid[1:]))
+	if err != nil {
+		return 0, nil, false
+	}
+
+	version, n := binary.Uvarint(decoded)
+	if n <= 0 || version != 1 {
+		return 0, nil, false
+	}
+	decoded = decoded[n:]
+
+	codec, n := binary.Uvarint(decoded)
+	if n <= 0 || (codec != dagPbCodec && codec != rawCodec) {
+		return 0, nil, false
+	}
+
+	return codec, decoded[n:], true
+}
+
+// encodeUnixfs encodes the UnixFS Data protobuf message.
+func encodeUnixfs(kind uint64, data []byte, filesize uint64, blocksizes []uint64) []byte {
+	buffer := protoVarint(nil, 1, kind)
+	if len(data) > 0 {
+		buffer = protoBytes(buffer, 2, data)
+	}
+	if kind == unixfsFileType {
+		buffer = protoVarint(buffer, 3, filesize)
+	}
+	for _, size := range blocksizes {
+		buffer = protoVarint(buffer, 4, size)
+	}
+
+	return buffer
+}
+
+// encodeDagNode encodes a dag-pb PBNode, links first as the canonical form
+// requires.
+func encodeDagNode(links []dagLink, data []byte) []byte {
+	var buffer []byte
+
+	for _, link := range links {
+		encoded := protoBytes(nil, 1, link.hash)
+		encoded = protoBytes(encoded, 2, []byte(link.name))
+		encoded = protoVarint(encoded, 3, link.tsize)
+
+		buffer = protoBytes(buffer, 2, encoded)
+	}
+
+	return protoBytes(buffer, 1, data)
+}
+
+func protoVarint(buffer []byte, field uint64, value uint64) []byte {
+	buffer = binary.AppendUvarint(buffer, field<<3)
+	return binary.AppendUvarint(buffer, value)
+}
+
+func protoBytes(buffer []byte, field uint64, value []byte) []byte {
+	buffer = binary.AppendUvarint(buffer, field<<3|2)
+	buffer = binary.AppendUvarint(buffer, uint64(len(value)))
+	return append(buffer, value...)
+}
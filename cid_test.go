@@ -0,0 +1,81 @@
+package client
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIpfsFileCID(t *testing.T) {
+
+	vectors := map[string][]byte{
+		"QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH": {},
+		"QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o": []byte("hello world\n"),
+	}
+
+	// Random content of one chunk, two chunks and one chunk more than a node
+	// can link to, CIDs computed with `ipfs add`.
+	for size, cid := range map[int]string{
+		262144:   "QmcJX3xVk2ZsSSzfDhEy8oTszQM7Wn492Pv4g5MGfU3sPc",
+		262145:   "QmSv3XSikn9wURsaMPc9rNXiKWNZK5fCFwZ6s2ELxbK8mk",
+		45875201: "QmNsgYYg557jCptFZaHqwWMVgTffsthDvYKoBqqXLfEw8q",
+	} {
+		data := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(data)
+		vectors[cid] = data
+	}
+
+	// The same content added with `ipfs add --cid-version=1`.
+	for size, cid := range map[int]string{
+		262144:   "bafkreifxvurgimkfxptvoilcillipzbqvu5p4dg7wtumopeawhgiugnjwm",
+		262145:   "bafybeihazrwaw6jap6b4ueakkihngg4kiuus5migbr6oe6sh36nqxhmdju",
+		45875201: "bafybeiczul5j7g25spi5hedbs7z7sqgej3ykzx5w22o7bkzlfvuhlkuime",
+	} {
+		data := make([]byte, size)
+		rand.New(rand.NewSource(int64(size))).Read(data)
+		vectors[cid] = data
+	}
+	vectors["bafkreihdwdcefgh4dqkjv67uzcmw7ojee6xedzdetojuzjevtenxquvyku"] = []byte{}
+	vectors["bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"] = []byte("hello world\n")
+
+	for expected, data := range vectors {
+		version := 0
+		if expected[0] == 'b' {
+			version = 1
+		}
+
+		writer := newUnixfsFileWriter(version, nil)
+		writer.Write(data)
+
+		cid, err := writer.Sum()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if cid != expected {
+			t.Fatalf(`CID of %d bytes: expected %s, got %s`, len(data), expected, cid)
+		}
+
+		hasher := newCidHasher("")
+		hasher.Write(data)
+		if _, matched, err := hasher.match(expected); err != nil || !matched {
+			t.Fatalf(`CID %s not matched by the hasher: %v`, expected, err)
+		}
+	}
+}
+
+func TestSameCID(t *testing.T) {
+
+	v0 := "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o"
+	v1 := "bafybeicg2rebjoofv4kbyovkw7af3rpiitvnl6i7ckcywaq6xjcxnc2mby"
+
+	if !sameCID(v0, v1) || !sameCID(v1, v0) || !sameCID(v0, v0) {
+		t.Fatalf(`CIDv0 and CIDv1 of the same node should match`)
+	}
+
+	// The raw CIDv1 of the same bytes addresses another node.
+	raw := "bafkreicg2rebjoofv4kbyovkw7af3rpiitvnl6i7ckcywaq6xjcxnc2mby"
+
+	if sameCID(v0, "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH") || sameCID(v0, "not-a-cid") || sameCID(v1, raw) {
+		t.Fatalf(`Different CIDs should not match`)
+	}
+}
@@ -1,260 +1,309 @@
 package client
 
 import (
-	"bytes"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"time"
 )
 
 const AlephApiUrl string = "https://api3.aleph.im"
+const SchedulerApiUrl string = "https://scheduler.api.aleph.sh"
+const IpfsGatewayUrl string = "https://ipfs.aleph.cloud"
+const DefaultUserAgent string = "go-twentysixcloud"
 
 type TwentySixClient struct {
-	account TwentySixAccount
-	channel string
-	apiUrl  string
-	http    http.Client
+	signer       Signer
+	owner        string
+	sync         bool
+	channel      string
+	nodes        *nodePool
+	broadcast    int
+	inlineLimit  int
+	offchainType MessageItemType
+	storeType    MessageItemType
+	progress     ProgressFunc
+	retryPolicy  RetryPolicy
+	schedulerUrl string
+	gatewayUrl   string
+	ipfsApiUrl   string
+	userAgent    string
+	http         *http.Client
 }
 
-func (client *TwentySixClient) GetMessageByHash(hash string) (Message, error) {
+// Sync returns a copy of the client broadcasting messages in sync mode: the
+// node only answers once it processed the message, so the returned
+// MessageResponse tells whether it was processed, rejected or is still
+// pending.
+func (client TwentySixClient) Sync(sync bool) TwentySixClient {
+	client.sync = sync
+	return client
+}
 
-	//https://api2.aleph.im/api/v0/messages.json?hashes=d51f34748974a1e652becd28c28249c2eb5a0cfaf8b718dde7121034d5733981
-	messageEndpoint := AlephApiUrl + "/api/v0/messages.json?hashes=" + hash
-	request, err := http.NewRequest("GET", messageEndpoint, bytes.NewBuffer([]byte("")))
-	if err != nil {
-		return Message{}, err
-	}
+// OnBehalfOf returns a copy of the client publishing for owner: message
+// contents carry the owner address while messages are still signed and sent
+// by the client signer. The owner must have authorized the signer address in
+// its security aggregate, see AddAuthorization.
+func (client TwentySixClient) OnBehalfOf(owner string) TwentySixClient {
+	client.owner = owner
+	return client
+}
 
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Accept", "application/json")
+// OnProgress returns a copy of the client reporting the progress of file
+// uploads to progress.
+func (client TwentySixClient) OnProgress(progress ProgressFunc) TwentySixClient {
+	client.progress = progress
+	return client
+}
 
-	response, err := client.http.Do(request)
-	if err != nil {
-		return Message{}, err
+// Address returns the address messages are published for: the delegating
+// owner when set with OnBehalfOf, the signer address otherwise.
+func (client *TwentySixClient) Address() string {
+	if client.owner != "" {
+		return client.owner
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
+	return client.signer.GetAddress()
+}
+
+func (client *TwentySixClient) GetMessageByHash(hash string) (Message, error) {
+	return client.GetMessageByHashWithContext(context.Background(), hash)
+}
+
+// GetMessageByHashWithContext returns a message whatever its processing
+// status: pending messages are returned as received by the node, rejected
+// ones as a *RejectionError. The content of storage and ipfs messages is
+// fetched when first decoded.
+func (client *TwentySixClient) GetMessageByHashWithContext(ctx context.Context, hash string) (Message, error) {
+	status, err := client.GetMessageStatusWithContext(ctx, hash)
 	if err != nil {
 		return Message{}, err
 	}
 
-	var result GetMessageResponse
-	if err := json.Unmarshal(resultBody, &result); err != nil { // Parse []byte to go struct pointer
-		return Message{}, err
+	switch {
+	case status.Status == RejectedMessageStatus:
+		return Message{}, status.rejection()
+	case status.Message != nil:
+		return client.lazyContent(*status.Message), nil
+	case len(status.Messages) > 0:
+		return client.lazyContent(status.Messages[0]), nil
 	}
 
-	defer response.Body.Close()
-
-	if result.PaginationTotal != 1 {
-		return Message{}, errors.New("message not found")
-	} else {
-		return result.Messages[0], nil
-	}
+	return Message{}, fmt.Errorf("%w: %s", ErrMessageNotFound, hash)
 }
 
 func (client *TwentySixClient) WaitMessageConfirmation(hash string, timeout int64, interval int64) error {
+	return client.WaitMessageConfirmationWithContext(context.Background(), hash, timeout, interval)
+}
+
+// WaitMessageConfirmationWithContext waits for the message to be confirmed
+// on chain. It fails as soon as the message is rejected or forgotten.
+func (client *TwentySixClient) WaitMessageConfirmationWithContext(ctx context.Context, hash string, timeout int64, interval int64) error {
 	var startAt int64 = time.Now().Unix()
-	var message Message
 
-	message, err := client.GetMessageByHash(hash)
-	if err != nil {
-		return err
-	}
+	for {
+		status, err := client.GetMessageStatusWithContext(ctx, hash)
+		if err != nil && !errors.Is(err, ErrMessageNotFound) {
+			return err
+		}
 
-	for !message.Confirmed {
-		time.Sleep(time.Duration(interval) * time.Second)
+		switch status.Status {
+		case RejectedMessageStatus:
+			return status.rejection()
+		case ForgottenMessageStatus:
+			return fmt.Errorf("message %s: %w", hash, ErrForgotten)
+		}
 
-		message, err = client.GetMessageByHash(hash)
-		if err != nil {
-			return err
+		if status.Message != nil && status.Message.Confirmed {
+			return nil
 		}
 
 		now := time.Now().Unix()
 		if now > startAt+timeout {
-			return errors.New("message confirmation timeout")
+			return fmt.Errorf("message %s confirmation: %w", hash, ErrTimeout)
 		}
-	}
 
-	return nil
+		if err := sleepContext(ctx, time.Duration(interval)*time.Second); err != nil {
+			return err
+		}
+	}
 }
 
 func (client *TwentySixClient) SendMessage(msgType MessageType, content interface{}, at float64) (Message, []byte, error) {
+	return client.SendMessageWithContext(context.Background(), msgType, content, at)
+}
 
-	message, err := PrepareMessage(client.account, client.channel, msgType, content, at)
+func (client *TwentySixClient) SendMessageWithContext(ctx context.Context, msgType MessageType, content interface{}, at float64) (Message, []byte, error) {
+	message, _, resultBody, err := client.publish(ctx, msgType, content, at)
 	if err != nil {
 		return Message{}, []byte{}, err
 	}
 
-	req := BroadcastRequest{
-		Message: message,
-		Sync:    false,
-	}
-
-	buff, err := json.Marshal(req)
-	if err != nil {
-		return Message{}, []byte{}, err
-	}
+	return message, resultBody, nil
+}
 
-	messageEndpoint := AlephApiUrl + "/api/v0/messages"
-	request, err := http.NewRequest("POST", messageEndpoint, bytes.NewBuffer(buff))
-	if err != nil {
-		return Message{}, []byte{}, err
-	}
+// BroadcastMessage sends an already signed message to the network. Since the
+// item hash of a signed message is deterministic, broadcasting the same
+// message again after a failure is safe and does not create a new message.
+func (client *TwentySixClient) BroadcastMessage(message Message) (MessageResponse, error) {
+	return client.BroadcastMessageWithContext(context.Background(), message)
+}
 
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Accept", "application/json")
+func (client *TwentySixClient) BroadcastMessageWithContext(ctx context.Context, message Message) (MessageResponse, error) {
+	response, _, err := client.broadcastMessage(ctx, message)
+	return response, err
+}
 
-	response, err := client.http.Do(request)
+// publish signs a new message and broadcasts it.
+func (client *TwentySixClient) publish(ctx context.Context, msgType MessageType, content interface{}, at float64) (Message, MessageResponse, []byte, error) {
+	message, err := client.PrepareMessageWithContext(ctx, msgType, content, at)
 	if err != nil {
-		return Message{}, []byte{}, err
+		return Message{}, MessageResponse{}, []byte{}, err
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
+	response, resultBody, err := client.broadcastMessage(ctx, message)
 	if err != nil {
-		return Message{}, []byte{}, err
+		return Message{}, MessageResponse{}, []byte{}, err
 	}
 
-	return message, resultBody, nil
+	return message, response, resultBody, nil
 }
 
-func (client *TwentySixClient) GetMessages(size uint64, page uint64, hashes []string, addresses []string, channels []string, msgTypes []MessageType) ([]Message, uint64, error) {
-	var messages []Message
-	body := &bytes.Buffer{}
-
-	messageEndpoint := AlephApiUrl + "/api/v0/messages.json?"
-
-	params := url.Values{}
-
-	params.Add("page", fmt.Sprint(page))
-	params.Add("size", fmt.Sprint(size))
-
-	for i := 0; i < len(hashes); i++ {
-		params.Add("hashes", hashes[i])
-	}
-	for i := 0; i < len(addresses); i++ {
-		params.Add("addresses", addresses[i])
-	}
-	for i := 0; i < len(channels); i++ {
-		params.Add("channels", channels[i])
-	}
-	for i := 0; i < len(msgTypes); i++ {
-		params.Add("msgTypes", string(msgTypes[i]))
+func (client *TwentySixClient) broadcastMessage(ctx context.Context, message Message) (MessageResponse, []byte, error) {
+	// Content of storage and ipfs messages is fetched by the node itself.
+	if !message.inline() {
+		message.ItemContent = ""
 	}
 
-	filteredEndpoint := messageEndpoint + params.Encode()
+	req := BroadcastRequest{
+		Message: message,
+		Sync:    client.sync,
+	}
 
-	request, err := http.NewRequest("GET", filteredEndpoint, body)
+	buff, err := json.Marshal(req)
 	if err != nil {
-		return messages, 0, err
+		return MessageResponse{}, []byte{}, err
 	}
 
-	request.Header.Add("Accept", "application/json")
-
-	response, err := client.http.Do(request)
+	response, err := client.broadcastApi(ctx, "/api/v0/messages", buff, "application/json")
 	if err != nil {
-		return messages, 0, err
+		return MessageResponse{}, []byte{}, err
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
+	resultBody, err := readResponse(response)
 	if err != nil {
-		return messages, 0, err
+		return MessageResponse{}, resultBody, err
 	}
 
-	var getMessageResponse GetMessageResponse
-	if err := json.Unmarshal(resultBody, &getMessageResponse); err != nil {
-		return messages, 0, err
+	var parsedRes MessageResponse
+	if err := json.Unmarshal(resultBody, &parsedRes); err != nil {
+		return MessageResponse{}, resultBody, err
 	}
 
-	for i := 0; i < len(getMessageResponse.Messages); i++ {
-		messages = append(messages, getMessageResponse.Messages[i])
-	}
+	parsedRes.StatusCode = response.StatusCode
 
-	var remainingItems uint64
-	if getMessageResponse.PaginationPage*getMessageResponse.PaginationPerPage > getMessageResponse.PaginationTotal {
-		remainingItems = 0
-	} else {
-		remainingItems = getMessageResponse.PaginationTotal - (getMessageResponse.PaginationPage * getMessageResponse.PaginationPerPage)
-	}
+	return parsedRes, resultBody, nil
+}
 
-	return messages, remainingItems, nil
+func (client *TwentySixClient) GetMessages(size uint64, page uint64, hashes []string, addresses []string, channels []string, msgTypes []MessageType) ([]Message, uint64, error) {
+	return client.GetMessagesWithContext(context.Background(), size, page, hashes, addresses, channels, msgTypes)
+}
+
+func (client *TwentySixClient) GetMessagesWithContext(ctx context.Context, size uint64, page uint64, hashes []string, addresses []string, channels []string, msgTypes []MessageType) ([]Message, uint64, error) {
+	return client.QueryMessagesWithContext(ctx, messageQuery(hashes, addresses, channels, msgTypes), size, page)
+}
+
+func messageQuery(hashes []string, addresses []string, channels []string, msgTypes []MessageType) MessageQuery {
+	return MessageQuery{Hashes: hashes, Addresses: addresses, Channels: channels, Types: msgTypes}
 }
 
 func (client *TwentySixClient) ForgetMessage(hash string) (MessageResponse, error) {
+	return client.ForgetMessageWithContext(context.Background(), hash)
+}
+
+func (client *TwentySixClient) ForgetMessageWithContext(ctx context.Context, hash string) (MessageResponse, error) {
 	now := float64(time.Now().UnixMilli()) / 1000
 
 	itemContent := ForgetMessageContent{
-		Address: client.account.Address,
+		Address: client.Address(),
 		Time:    now,
 		Hashes:  []string{hash},
 	}
 
-	msgContent, err := json.Marshal(itemContent)
+	_, response, _, err := client.publish(ctx, ForgetMessageType, itemContent, now)
 	if err != nil {
 		return MessageResponse{}, err
 	}
 
-	contentHash := sha256.Sum256(msgContent)
-
-	message := Message{
-		Type:    ForgetMessageType,
-		Chain:   EthereumChain,
-		Sender:  client.account.Address,
-		Time:    now,
-		Channel: client.channel,
-
-		ItemHash:    hex.EncodeToString(contentHash[:]),
-		ItemType:    InlineMessageItem,
-		ItemContent: string(msgContent),
-	}
+	return response, nil
+}
 
-	message.SignMessage(client.account)
+// sleepContext waits for duration, returning early with the context error
+// if ctx is done first.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
 
-	req := BroadcastRequest{
-		Message: message,
-		Sync:    false,
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
 
-	buff, err := json.Marshal(req)
+func (client *TwentySixClient) newRequest(ctx context.Context, method string, endpoint string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
-		return MessageResponse{}, err
+		return nil, err
 	}
 
-	storeEndpoint := AlephApiUrl + "/api/v0/messages"
-	request, err := http.NewRequest("POST", storeEndpoint, bytes.NewBuffer(buff))
-	if err != nil {
-		return MessageResponse{}, err
+	request.Header.Set("Accept", "application/json")
+	if client.userAgent != "" {
+		request.Header.Set("User-Agent", client.userAgent)
 	}
 
-	request.Header.Add("Content-Type", "application/json")
-	request.Header.Add("Accept", "application/json")
+	return request, nil
+}
 
-	response, err := client.http.Do(request)
-	if err != nil {
-		return MessageResponse{}, err
+func NewTwentySixClient(signer Signer, channel string, apiUrl string) TwentySixClient {
+	return NewTwentySixClientWithOptions(signer, WithChannel(channel), WithApiUrl(apiUrl))
+}
+
+// NewTwentySixClientWithOptions creates a client talking to AlephApiUrl,
+// SchedulerApiUrl and IpfsGatewayUrl with a default http.Client, unless
+// overridden by options. No IPFS API is configured by default.
+func NewTwentySixClientWithOptions(signer Signer, options ...ClientOption) TwentySixClient {
+	client := TwentySixClient{
+		signer:       signer,
+		nodes:        newNodePool([]string{AlephApiUrl}),
+		inlineLimit:  DefaultInlineLimit,
+		offchainType: StorageMessageItem,
+		storeType:    StorageMessageItem,
+		retryPolicy:  DefaultRetryPolicy,
+		schedulerUrl: SchedulerApiUrl,
+		gatewayUrl:   IpfsGatewayUrl,
+		userAgent:    DefaultUserAgent,
+		http:         &http.Client{},
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
-	if err != nil {
-		return MessageResponse{}, err
+	for _, option := range options {
+		option(&client)
 	}
 
-	var parsedRes MessageResponse
-	json.Unmarshal(resultBody, &parsedRes)
+	return client
+}
 
-	return parsedRes, nil
+// Processed reports whether the node processed the message before answering,
+// which only happens in sync mode.
+func (response MessageResponse) Processed() bool {
+	return response.Status == ProcessedMessageStatus
 }
 
-func NewTwentySixClient(acc TwentySixAccount, channel string, apiUrl string) TwentySixClient {
-	return TwentySixClient{
-		account: acc,
-		channel: channel,
-		apiUrl:  apiUrl,
-		http:    http.Client{},
-	}
+// Pending reports whether the message was accepted but not processed yet.
+func (response MessageResponse) Pending() bool {
+	return response.Status == PendingMessageStatus || (response.Status == "" && response.StatusCode == http.StatusAccepted)
 }
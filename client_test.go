@@ -0,0 +1,275 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestContextCancellation(t *testing.T) {
+
+	acc, err := NewTwentySixAccountFromPrivateKey("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client := NewTwentySixClient(acc, "TEST", server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var result interface{}
+	if err := client.GetAggregateWithContext(ctx, acc.Address, "test", &result); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(`Request was not cancelled by its context: %v`, err)
+	}
+}
+
+func TestSleepContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if err := sleepContext(ctx, time.Minute); !errors.Is(err, context.Canceled) {
+		t.Fatalf(`sleepContext ignored cancellation: %v`, err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Fatalf(`sleepContext did not return early`)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+
+	acc, err := NewTwentySixAccountFromPrivateKey("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		if r.URL.Path != "/api/v0/allocation/vm" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"vm_hash":"vm","vm_type":"instance"}`))
+	}))
+	defer server.Close()
+
+	client := NewTwentySixClientWithOptions(acc,
+		WithChannel("TEST"),
+		WithApiUrl(server.URL+"/"),
+		WithSchedulerUrl(server.URL),
+		WithUserAgent("test-agent"),
+		WithTimeout(time.Second),
+	)
+
+	if client.Nodes()[0].Url != server.URL || client.channel != "TEST" {
+		t.Fatalf(`Options were not applied`)
+	}
+
+	allocation, err := client.GetInstanceState("vm")
+	if err != nil {
+		t.Fatalf(`GetInstanceState failed: %v`, err)
+	}
+
+	if allocation.VmHash != "vm" || userAgent != "test-agent" {
+		t.Fatalf(`Scheduler url or user agent options were ignored`)
+	}
+}
+
+func TestSyncBroadcast(t *testing.T) {
+
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req BroadcastRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Sync {
+			w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"processed"}`))
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+	}))
+
+	_, response, err := client.CreatePost(PostMessageContent{Type: "test", Content: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Pending() || response.Processed() || response.StatusCode != http.StatusAccepted {
+		t.Fatalf(`Async broadcast should be pending: %+v`, response)
+	}
+
+	syncClient := client.Sync(true)
+	_, response, err = syncClient.CreatePost(PostMessageContent{Type: "test", Content: "Hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Pending() || !response.Processed() || response.StatusCode != http.StatusOK {
+		t.Fatalf(`Sync broadcast should be processed: %+v`, response)
+	}
+}
+
+func TestWaitMessageStatus(t *testing.T) {
+
+	polls := 0
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		switch {
+		case r.URL.Path == "/api/v0/messages/rejected" && polls > 1:
+			w.Write([]byte(`{"item_hash":"rejected","status":"rejected","error_code":1,"details":{"errors":["bad content"]}}`))
+		case r.URL.Path == "/api/v0/messages/processed":
+			w.Write([]byte(`{"item_hash":"processed","status":"processed","message":{"item_hash":"processed","confirmed":true}}`))
+		default:
+			w.Write([]byte(`{"item_hash":"rejected","status":"pending","messages":[{"item_hash":"rejected"}]}`))
+		}
+	}))
+
+	message, err := client.GetMessageByHash("rejected")
+	if err != nil || message.ItemHash != "rejected" {
+		t.Fatalf(`Pending message not returned: %v`, err)
+	}
+
+	_, err = client.WaitMessageStatus("rejected", time.Millisecond)
+	var rejection *RejectionError
+	if !errors.Is(err, ErrRejected) || !errors.As(err, &rejection) || rejection.ErrorCode != 1 {
+		t.Fatalf(`Rejection not reported: %v`, err)
+	}
+
+	if err := client.WaitMessageConfirmation("processed", 1, 1); err != nil {
+		t.Fatalf(`WaitMessageConfirmation failed: %v`, err)
+	}
+
+	// A deadline exceeded during the status request is a timeout too.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := client.WaitMessageStatusWithContext(ctx, "pending", time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf(`Expected ErrTimeout, got %v`, err)
+	}
+}
+
+func TestCreateMessageTypes(t *testing.T) {
+
+	var broadcast Message
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req BroadcastRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		broadcast = req.Message
+
+		w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+	}))
+
+	if _, _, err := client.CreatePost(PostMessageContent{Type: "test", Content: "Hello"}); err != nil || broadcast.Type != PostMessageType {
+		t.Fatalf(`Post sent as %s: %v`, broadcast.Type, err)
+	}
+
+	if _, _, err := client.CreateProgram(ProgramMessageContent{}); err != nil || broadcast.Type != ProgramMessageType {
+		t.Fatalf(`Program sent as %s: %v`, broadcast.Type, err)
+	}
+}
+
+func TestOffchainContent(t *testing.T) {
+
+	files := map[string][]byte{}
+	var broadcast Message
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v0/storage/add_file":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			content, _ := io.ReadAll(file)
+			hash := sha256.Sum256(content)
+			files[hex.EncodeToString(hash[:])] = content
+
+			json.NewEncoder(w).Encode(StoreIPFSFileResponse{Hash: hex.EncodeToString(hash[:]), Status: SucceedMessageStatus})
+		case r.URL.Path == "/api/v0/messages":
+			var req BroadcastRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			broadcast = req.Message
+
+			w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+		case strings.HasPrefix(r.URL.Path, "/api/v0/messages/"):
+			json.NewEncoder(w).Encode(MessageStatusResponse{ItemHash: broadcast.ItemHash, Status: PendingMessageStatus, Message: &broadcast})
+		case strings.HasPrefix(r.URL.Path, "/api/v0/storage/raw/"):
+			w.Write(files[strings.TrimPrefix(r.URL.Path, "/api/v0/storage/raw/")])
+		case strings.HasPrefix(r.URL.Path, "/ipfs/"):
+			w.Write(files[strings.TrimPrefix(r.URL.Path, "/ipfs/")])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), WithInlineLimit(64))
+	WithIpfsGatewayUrl(server.URL)(&client)
+
+	message, _, err := client.CreatePost(PostMessageContent{Type: "test", Content: strings.Repeat("large ", 100)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.ItemType != StorageMessageItem || broadcast.ItemType != StorageMessageItem || broadcast.ItemContent != "" {
+		t.Fatalf(`Large content should be sent as a storage item: %+v`, broadcast)
+	}
+
+	if _, err := broadcast.Content(); !errors.Is(err, ErrContentNotInline) {
+		t.Fatalf(`Expected ErrContentNotInline, got %v`, err)
+	}
+
+	fetched, err := client.GetMessageByHash(message.ItemHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fetched.Verify(); err != nil {
+		t.Fatalf(`Fetched message does not verify: %v`, err)
+	}
+
+	payload, err := DecodePayload[string](fetched)
+	if err != nil || payload != strings.Repeat("large ", 100) {
+		t.Fatalf(`Unexpected payload %q: %v`, payload, err)
+	}
+
+	files[message.ItemHash] = []byte(`{"type":"test","content":"tampered"}`)
+
+	tampered, err := client.GetMessageByHash(message.ItemHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DecodePayload[string](tampered); !errors.Is(err, ErrItemHashMismatch) {
+		t.Fatalf(`Expected ErrItemHashMismatch, got %v`, err)
+	}
+
+	// ipfs items are fetched from the gateway rather than the API nodes.
+	content := []byte(`{"type":"test","content":"ipfs"}`)
+	cid, err := contentItemHash(IpfsMessageItem, content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files[cid] = content
+
+	resolved, err := client.ResolveContent(Message{ItemHash: cid, ItemType: IpfsMessageItem})
+	if err != nil || resolved.ItemContent != string(content) {
+		t.Fatalf(`ipfs content not resolved from the gateway: %v`, err)
+	}
+}
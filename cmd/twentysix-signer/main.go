@@ -0,0 +1,34 @@
+// Command twentysix-signer is a reference remote signing service. It loads an
+// encrypted keystore and answers personal_sign, eth_sign and eth_accounts
+// JSON-RPC calls so that publishers can use client.RemoteSigner.
+//
+// The keystore passphrase is read from the TWENTYSIX_KEYSTORE_PASSPHRASE
+// environment variable.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"os"
+
+	client "github.com/bliiitz/go-twentysixcloud"
+)
+
+func main() {
+	keystorePath := flag.String("keystore", "", "path to the encrypted keystore file")
+	listen := flag.String("listen", "127.0.0.1:8550", "address to listen on")
+	flag.Parse()
+
+	if *keystorePath == "" {
+		log.Fatal("missing -keystore")
+	}
+
+	account, err := client.NewTwentySixAccountFromKeystore(*keystorePath, os.Getenv("TWENTYSIX_KEYSTORE_PASSPHRASE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("signing for %s on %s", account.Address, *listen)
+	log.Fatal(http.ListenAndServe(*listen, client.NewSigningServer(account)))
+}
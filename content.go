@@ -0,0 +1,309 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// DefaultInlineLimit is the largest content, in bytes, sent inline. Larger
+// content is uploaded and referenced by the message.
+const DefaultInlineLimit = 50000
+
+var (
+	ErrUnknownMessageType = errors.New("unknown message type")
+	ErrContentNotInline   = errors.New("message content is not inline and was not fetched")
+	ErrNoPayload          = errors.New("message type has no user payload")
+)
+
+// Content decodes the item content into the struct matching the message
+// type: a *AggregateMessageContent, *PostMessageContent,
+// *StoreMessageContent, *ForgetMessageContent, *ProgramMessageContent or
+// *InstanceMessageContent. The content of storage and ipfs messages returned
+// by iterators and subscriptions is fetched from the node on first use, other
+// messages must have been resolved first, see TwentySixClient.ResolveContent.
+func (msg Message) Content() (interface{}, error) {
+	var content interface{}
+
+	switch msg.Type {
+	case AggregateMessageType:
+		content = &AggregateMessageContent{}
+	case PostMessageType:
+		content = &PostMessageContent{}
+	case StoreMessageType:
+		content = &StoreMessageContent{}
+	case ForgetMessageType:
+		content = &ForgetMessageContent{}
+	case ProgramMessageType:
+		content = &ProgramMessageContent{}
+	case InstanceMessageType:
+		content = &InstanceMessageContent{}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMessageType, msg.Type)
+	}
+
+	if err := msg.decodeContent(content); err != nil {
+		return nil, err
+	}
+
+	return content, nil
+}
+
+func (msg Message) inline() bool {
+	return msg.ItemType == InlineMessageItem || msg.ItemType == ""
+}
+
+func (msg Message) decodeContent(content interface{}) error {
+	itemContent := msg.ItemContent
+
+	if !msg.inline() && itemContent == "" {
+		if msg.fetcher == nil {
+			return fmt.Errorf("%w: message %s is %s", ErrContentNotInline, msg.ItemHash, msg.ItemType)
+		}
+
+		fetched, err := msg.fetcher.fetch(msg)
+		if err != nil {
+			return err
+		}
+		itemContent = fetched
+	}
+
+	if err := json.Unmarshal([]byte(itemContent), content); err != nil {
+		return fmt.Errorf("message %s content: %w", msg.ItemHash, err)
+	}
+
+	return nil
+}
+
+// DecodeContent decodes the whole item content of msg into T.
+func DecodeContent[T any](msg Message) (T, error) {
+	var content T
+	err := msg.decodeContent(&content)
+
+	return content, err
+}
+
+// DecodePayload decodes the user payload of a POST or AGGREGATE message, the
+// content field of its item content, into T.
+func DecodePayload[T any](msg Message) (T, error) {
+	var payload T
+
+	if msg.Type != PostMessageType && msg.Type != AggregateMessageType {
+		return payload, fmt.Errorf("%w: %s", ErrNoPayload, msg.Type)
+	}
+
+	var content struct {
+		Content json.RawMessage `json:"content"`
+	}
+
+	if err := msg.decodeContent(&content); err != nil {
+		return payload, err
+	}
+
+	if err := json.Unmarshal(content.Content, &payload); err != nil {
+		return payload, fmt.Errorf("message %s payload: %w", msg.ItemHash, err)
+	}
+
+	return payload, nil
+}
+
+// PrepareMessage signs a message as the package PrepareMessage does, except
+// that content larger than the client inline limit is first uploaded to
+// storage or IPFS and referenced by its hash. The returned message keeps the
+// content so that Content works on it.
+func (client *TwentySixClient) PrepareMessage(msgType MessageType, content interface{}, at float64) (Message, error) {
+	return client.PrepareMessageWithContext(context.Background(), msgType, content, at)
+}
+
+func (client *TwentySixClient) PrepareMessageWithContext(ctx context.Context, msgType MessageType, content interface{}, at float64) (Message, error) {
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if client.inlineLimit <= 0 || len(encoded) <= client.inlineLimit {
+		return PrepareMessageWithContext(ctx, client.signer, client.channel, msgType, content, at)
+	}
+
+	hash, err := client.uploadContent(ctx, client.offchainType, encoded)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return signMessage(ctx, client.signer, client.channel, msgType, at, client.offchainType, hash, string(encoded))
+}
+
+// uploadContent stores content on the node and checks that the node
+// addresses it by the expected hash.
+func (client *TwentySixClient) uploadContent(ctx context.Context, itemType MessageItemType, content []byte) (string, error) {
+	expected, err := contentItemHash(itemType, content)
+	if err != nil {
+		return "", err
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	filepart, err := writer.CreateFormFile("file", expected+".json")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := filepart.Write(content); err != nil {
+		return "", err
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	endpoint := "/api/v0/storage/add_file"
+	if itemType == IpfsMessageItem {
+		endpoint = "/api/v0/ipfs/add_file"
+	}
+
+	response, err := client.doApi(ctx, "POST", endpoint, body.Bytes(), writer.FormDataContentType())
+	if err != nil {
+		return "", err
+	}
+
+	resultBody, err := readResponse(response)
+	if err != nil {
+		return "", err
+	}
+
+	var uploaded StoreIPFSFileResponse
+	if err := json.Unmarshal(resultBody, &uploaded); err != nil {
+		return "", err
+	}
+
+	if err := verifyItemHash(itemType, uploaded.Hash, content); err != nil {
+		return "", fmt.Errorf("%w: content uploaded as %s, expected %s", err, uploaded.Hash, expected)
+	}
+
+	return uploaded.Hash, nil
+}
+
+func (client *TwentySixClient) ResolveContent(msg Message) (Message, error) {
+	return client.ResolveContentWithContext(context.Background(), msg)
+}
+
+// ResolveContentWithContext fetches the content of a storage or ipfs message
+// into its ItemContent, after checking it against the item hash. Inline
+// messages and messages already resolved are returned as is.
+func (client *TwentySixClient) ResolveContentWithContext(ctx context.Context, msg Message) (Message, error) {
+	if msg.inline() || msg.ItemContent != "" {
+		return msg, nil
+	}
+
+	response, err := client.fetchRaw(ctx, msg.ItemType, msg.ItemHash, nil)
+	if err != nil {
+		return msg, err
+	}
+
+	content, err := readResponse(response)
+	if err != nil {
+		return msg, fmt.Errorf("message %s content: %w", msg.ItemHash, err)
+	}
+
+	if err := verifyItemHash(msg.ItemType, msg.ItemHash, content); err != nil {
+		return msg, fmt.Errorf("message %s content: %w", msg.ItemHash, err)
+	}
+
+	msg.ItemContent = string(content)
+
+	return msg, nil
+}
+
+// fetchRaw requests the content of a storage or ipfs item with the given
+// request headers: storage items are read from the API nodes, ipfs items
+// from the IPFS gateway.
+func (client *TwentySixClient) fetchRaw(ctx context.Context, itemType MessageItemType, hash string, header http.Header) (*http.Response, error) {
+	if itemType == IpfsMessageItem {
+		return client.retry(ctx, func() (*http.Response, error) {
+			return client.sendApi(ctx, "GET", client.gatewayUrl+"/ipfs/"+url.PathEscape(hash), nil, header)
+		})
+	}
+
+	return client.doApiHeader(ctx, "GET", "/api/v0/storage/raw/"+url.PathEscape(hash), nil, header)
+}
+
+// contentFetcher resolves the content of a message once, however many times
+// the message is copied and decoded.
+type contentFetcher struct {
+	client  *TwentySixClient
+	once    sync.Once
+	content string
+	err     error
+}
+
+// lazyContent attaches a fetcher to storage and ipfs messages whose content
+// was not resolved yet.
+func (client *TwentySixClient) lazyContent(msg Message) Message {
+	if !msg.inline() && msg.ItemContent == "" {
+		msg.fetcher = &contentFetcher{client: client}
+	}
+
+	return msg
+}
+
+func (fetcher *contentFetcher) fetch(msg Message) (string, error) {
+	fetcher.once.Do(func() {
+		resolved, err := fetcher.client.ResolveContent(msg)
+		fetcher.content, fetcher.err = resolved.ItemContent, err
+	})
+
+	return fetcher.content, fetcher.err
+}
+
+// contentItemHash returns the item hash of content for the given item type:
+// its sha256 for inline and storage items, its CIDv0 for ipfs items.
+func contentItemHash(itemType MessageItemType, content []byte) (string, error) {
+	switch itemType {
+	case InlineMessageItem, StorageMessageItem, "":
+		hash := sha256.Sum256(content)
+		return hex.EncodeToString(hash[:]), nil
+	case IpfsMessageItem:
+		return ipfsFileCID(bytes.NewReader(content))
+	}
+
+	return "", fmt.Errorf("unknown item type %q", itemType)
+}
+
+// verifyItemHash checks content against its item hash, a CID of either
+// version for ipfs items.
+func verifyItemHash(itemType MessageItemType, hash string, content []byte) error {
+	if itemType == IpfsMessageItem {
+		hasher := newCidHasher(hash)
+		hasher.Write(content)
+
+		_, matched, err := hasher.match(hash)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return ErrItemHashMismatch
+		}
+
+		return nil
+	}
+
+	computed, err := contentItemHash(itemType, content)
+	if err != nil {
+		return err
+	}
+
+	if computed != hash {
+		return ErrItemHashMismatch
+	}
+
+	return nil
+}
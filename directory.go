@@ -0,0 +1,333 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+var ErrNoIpfsApi = errors.New("no ipfs api configured, see WithIpfsApiUrl")
+
+// DirectoryOptions selects the files of a directory upload. Patterns use the
+// path.Match syntax and are matched against both the slash separated path
+// relative to the directory and the file name, so "*.log" excludes log files
+// at any depth and "docs/*" only the files directly under docs. Excluded
+// directories are skipped entirely. Without Include patterns every file is
+// included.
+type DirectoryOptions struct {
+	Include []string
+	Exclude []string
+}
+
+// DirectoryEntry is a file of an uploaded directory.
+type DirectoryEntry struct {
+	Path string
+	Cid  string
+	Size int64
+}
+
+// DirectoryManifest lists the CID of an uploaded directory and of each of
+// its files.
+type DirectoryManifest struct {
+	Root  string
+	Files []DirectoryEntry
+}
+
+// directoryNode is a file or directory of the tree being uploaded.
+type directoryNode struct {
+	name     string
+	path     string
+	dir      bool
+	size     int64
+	children []*directoryNode
+}
+
+func (options DirectoryOptions) matches(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, relPath); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, path.Base(relPath)); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (options DirectoryOptions) validate() error {
+	for _, pattern := range append(append([]string{}, options.Include...), options.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// listDirectory walks root, in lexical order as IPFS links are sorted, and
+// returns the tree of selected regular files. Directories left without
+// files are dropped.
+func listDirectory(root string, options DirectoryOptions) (*directoryNode, error) {
+	tree := &directoryNode{name: filepath.Base(root), dir: true}
+	dirs := map[string]*directoryNode{".": tree}
+
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if options.matches(options.Exclude, rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		node := &directoryNode{name: entry.Name(), path: rel, dir: entry.IsDir()}
+
+		switch {
+		case entry.IsDir():
+			dirs[rel] = node
+		case entry.Type().IsRegular():
+			if len(options.Include) > 0 && !options.matches(options.Include, rel) {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			node.size = info.Size()
+		default:
+			// Symlinks and special files are not uploaded.
+			return nil
+		}
+
+		parent := dirs[path.Dir(rel)]
+		parent.children = append(parent.children, node)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tree.prune()
+
+	return tree, nil
+}
+
+// prune drops the directories without files below node.
+func (node *directoryNode) prune() {
+	children := node.children[:0]
+	for _, child := range node.children {
+		if child.dir {
+			child.prune()
+			if len(child.children) == 0 {
+				continue
+			}
+		}
+
+		children = append(children, child)
+	}
+
+	node.children = children
+}
+
+func (node *directoryNode) totalSize() int64 {
+	size := node.size
+	for _, child := range node.children {
+		size += child.totalSize()
+	}
+
+	return size
+}
+
+// StoreDirectory uploads the files of the directory at dirPath selected by
+// options as a single UnixFS directory DAG through the IPFS API set with
+// WithIpfsApiUrl, and publishes one STORE message for its root CID. The DAG
+// is also built locally: the root CID returned by the IPFS node must match
+// it. The manifest gives the CID of every uploaded file.
+//
+// The upload is streamed: it is neither retried nor failed over.
+func (client *TwentySixClient) StoreDirectory(dirPath string, options DirectoryOptions) (Message, DirectoryManifest, error) {
+	return client.StoreDirectoryWithContext(context.Background(), dirPath, options)
+}
+
+func (client *TwentySixClient) StoreDirectoryWithContext(ctx context.Context, dirPath string, options DirectoryOptions) (Message, DirectoryManifest, error) {
+	if client.ipfsApiUrl == "" {
+		return Message{}, DirectoryManifest{}, ErrNoIpfsApi
+	}
+
+	if err := options.validate(); err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	dirPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	tree, err := listDirectory(dirPath, options)
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	var manifest DirectoryManifest
+	progress := &progressWriter{total: tree.totalSize(), progress: client.progress}
+
+	response, err := client.streamMultipart(func(body io.Reader, contentType string) (*http.Response, error) {
+		query := url.Values{"pin": {"true"}, "cid-version": {"0"}, "progress": {"false"}}
+		return client.sendStream(ctx, "POST", client.ipfsApiUrl+"/api/v0/add?"+query.Encode(), body, contentTypeHeader(contentType))
+	}, func(writer *multipart.Writer) error {
+		root, err := writeDirectoryParts(writer, dirPath, tree, progress, &manifest)
+		if err != nil {
+			return err
+		}
+
+		manifest.Root = formatCID(root.hash)
+
+		return writer.Close()
+	})
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	resultBody, err := readResponse(response)
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	added, err := addedRoot(resultBody, tree.name)
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	if !sameCID(added, manifest.Root) {
+		return Message{}, DirectoryManifest{}, fmt.Errorf("%w: directory added to ipfs as %s, expected %s", ErrItemHashMismatch, added, manifest.Root)
+	}
+
+	now := float64(time.Now().UnixMilli()) / 1000
+
+	message, _, _, err := client.publish(ctx, StoreMessageType, StoreMessageContent{
+		Address:  client.Address(),
+		Time:     now,
+		ItemHash: manifest.Root,
+		ItemType: IpfsMessageItem,
+	}, now)
+	if err != nil {
+		return Message{}, DirectoryManifest{}, err
+	}
+
+	return message, manifest, nil
+}
+
+// writeDirectoryParts writes node and what is below it in the multipart
+// format of the IPFS add API, directories before their content, and returns
+// the link to node in the DAG.
+func writeDirectoryParts(writer *multipart.Writer, root string, node *directoryNode, progress *progressWriter, manifest *DirectoryManifest) (dagLink, error) {
+	name := path.Join(filepath.Base(root), node.path)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, url.QueryEscape(name)))
+
+	if !node.dir {
+		header.Set("Content-Type", "application/octet-stream")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return dagLink{}, err
+		}
+
+		link, err := writeDirectoryFile(part, filepath.Join(root, filepath.FromSlash(node.path)), node.size, progress)
+		if err != nil {
+			return dagLink{}, err
+		}
+
+		manifest.Files = append(manifest.Files, DirectoryEntry{Path: node.path, Cid: formatCID(link.hash), Size: node.size})
+
+		return link, nil
+	}
+
+	header.Set("Content-Type", "application/x-directory")
+	if _, err := writer.CreatePart(header); err != nil {
+		return dagLink{}, err
+	}
+
+	links := make([]dagLink, len(node.children))
+	for i, child := range node.children {
+		link, err := writeDirectoryParts(writer, root, child, progress, manifest)
+		if err != nil {
+			return dagLink{}, err
+		}
+
+		link.name = child.name
+		links[i] = link
+	}
+
+	return unixfsDirectory(links)
+}
+
+func writeDirectoryFile(part io.Writer, filePath string, size int64, progress *progressWriter) (dagLink, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return dagLink{}, err
+	}
+
+	defer file.Close()
+
+	progress.writer = part
+	hasher := newUnixfsFileWriter(0, nil)
+
+	written, err := io.Copy(io.MultiWriter(progress, hasher), file)
+	if err != nil {
+		return dagLink{}, err
+	}
+
+	if written != size {
+		return dagLink{}, fmt.Errorf("%s changed during the upload", filePath)
+	}
+
+	return hasher.root()
+}
+
+// addedRoot finds the CID of the uploaded directory in the newline
+// delimited JSON answered by the IPFS add API.
+func addedRoot(body []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var added struct {
+			Name string
+			Hash string
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &added); err != nil {
+			return "", err
+		}
+
+		if added.Name == name {
+			return added.Hash, nil
+		}
+	}
+
+	return "", fmt.Errorf("directory %s missing from the ipfs add response", name)
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+func TestStoreDirectory(t *testing.T) {
+
+	root := filepath.Join(t.TempDir(), "site")
+	files := map[string]string{
+		"index.html":          "<html></html>\n",
+		"hello.txt":           "hello world\n",
+		"assets/a.txt":        "aaa\n",
+		"assets/b.txt":        "bbb\n",
+		"debug.log":           "excluded\n",
+		"node_modules/lib.js": "excluded\n",
+	}
+
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// CIDs computed with `ipfs add -r`.
+	expectedRoot := "QmaGH7Bd2r1tiWsRTCtLAcPxW9iN4dqDqLR3ZjsQFoYVJQ"
+	returnedRoot := expectedRoot
+
+	var parts []string
+	var broadcast BroadcastRequest
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/add":
+			reader, err := r.MultipartReader()
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			parts = nil
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				name, _ := url.QueryUnescape(part.FileName())
+				parts = append(parts, name)
+				io.Copy(io.Discard, part)
+			}
+
+			fmt.Fprintf(w, "{\"Name\":\"site/hello.txt\",\"Hash\":\"QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o\",\"Size\":\"20\"}\n")
+			fmt.Fprintf(w, "{\"Name\":\"site\",\"Hash\":\"%s\",\"Size\":\"400\"}\n", returnedRoot)
+		case "/api/v0/messages":
+			json.NewDecoder(r.Body).Decode(&broadcast)
+			w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	options := DirectoryOptions{Exclude: []string{"*.log", "node_modules"}}
+
+	if _, _, err := client.StoreDirectory(root, options); !errors.Is(err, ErrNoIpfsApi) {
+		t.Fatalf(`Expected ErrNoIpfsApi, got %v`, err)
+	}
+
+	WithIpfsApiUrl(server.URL)(&client)
+
+	message, manifest, err := client.StoreDirectoryWithContext(context.Background(), root, options)
+	if err != nil {
+		t.Fatalf(`StoreDirectory failed: %v`, err)
+	}
+
+	if manifest.Root != expectedRoot || len(manifest.Files) != 4 {
+		t.Fatalf(`Unexpected manifest %+v`, manifest)
+	}
+
+	if manifest.Files[2].Path != "hello.txt" || manifest.Files[2].Cid != "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o" {
+		t.Fatalf(`Unexpected manifest entry %+v`, manifest.Files[2])
+	}
+
+	expectedParts := []string{"site", "site/assets", "site/assets/a.txt", "site/assets/b.txt", "site/hello.txt", "site/index.html"}
+	if fmt.Sprint(parts) != fmt.Sprint(expectedParts) {
+		t.Fatalf(`Unexpected uploaded entries %q`, parts)
+	}
+
+	content, err := DecodeContent[StoreMessageContent](broadcast.Message)
+	if err != nil || content.ItemHash != expectedRoot || content.ItemType != IpfsMessageItem || message.ItemHash != broadcast.Message.ItemHash {
+		t.Fatalf(`Unexpected STORE message %+v: %v`, broadcast.Message, err)
+	}
+
+	tree, err := listDirectory(root, DirectoryOptions{Include: []string{"assets/*"}})
+	if err != nil || len(tree.children) != 1 || tree.children[0].path != "assets" || len(tree.children[0].children) != 2 {
+		t.Fatalf(`Only the assets directory should be included: %v`, err)
+	}
+
+	returnedRoot = "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn"
+	if _, _, err := client.StoreDirectory(root, options); !errors.Is(err, ErrItemHashMismatch) {
+		t.Fatalf(`Expected ErrItemHashMismatch, got %v`, err)
+	}
+}
+
+func TestEmptyUnixfsDirectory(t *testing.T) {
+
+	link, err := unixfsDirectory(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cid := base58.Encode(link.hash); cid != "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+		t.Fatalf(`Unexpected empty directory CID %s`, cid)
+	}
+}
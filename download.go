@@ -0,0 +1,311 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var ErrUnsupportedHash = errors.New("unsupported file hash")
+
+// fileItemType tells how a file is addressed from its hash: a sha256 for
+// storage items, a CIDv0 or a dag-pb or raw CIDv1 for ipfs items.
+func fileItemType(hash string) (MessageItemType, error) {
+	if len(hash) == 2*sha256.Size {
+		if _, err := hex.DecodeString(hash); err == nil {
+			return StorageMessageItem, nil
+		}
+	}
+
+	if _, multihash, ok := parseCID(hash); ok && len(multihash) == 2+sha256.Size && multihash[0] == multihashSha256 && multihash[1] == sha256.Size {
+		return IpfsMessageItem, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedHash, hash)
+}
+
+// fileHasher computes the hash of a file as it is written to it.
+type fileHasher interface {
+	io.Writer
+	Sum() (string, error)
+}
+
+type sha256Hasher struct {
+	hash.Hash
+}
+
+func (hasher sha256Hasher) Sum() (string, error) {
+	return hex.EncodeToString(hasher.Hash.Sum(nil)), nil
+}
+
+// ipfsHasher computes the CID of a file in the version of the expected one.
+type ipfsHasher struct {
+	*cidHasher
+	cid string
+}
+
+func (hasher ipfsHasher) Sum() (string, error) {
+	sum, _, err := hasher.match(hasher.cid)
+	return sum, err
+}
+
+func newFileHasher(itemType MessageItemType, hash string) fileHasher {
+	if itemType == IpfsMessageItem {
+		return ipfsHasher{newCidHasher(hash), hash}
+	}
+
+	return sha256Hasher{sha256.New()}
+}
+
+// openRaw requests the file content from offset, length bytes or up to the
+// end when length is negative. Storage files are read from the API nodes,
+// ipfs files from the IPFS gateway.
+func (client *TwentySixClient) openRaw(ctx context.Context, hash string, itemType MessageItemType, offset int64, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	switch {
+	case length == 0:
+		return io.NopCloser(strings.NewReader("")), nil
+	case length > 0:
+		header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case offset > 0:
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := client.fetchRaw(ctx, itemType, hash, header)
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		return response.Body, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing left after offset: the file was already read entirely.
+		response.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	case http.StatusOK:
+	default:
+		_, err := readResponse(response)
+		return nil, fmt.Errorf("file %s: %w", hash, err)
+	}
+
+	// The node ignored the range, skip to it.
+	if _, err := io.CopyN(io.Discard, response.Body, offset); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	if length < 0 {
+		return response.Body, nil
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(response.Body, length), response.Body}, nil
+}
+
+// OpenFile streams the content of the file addressed by hash, a sha256 for
+// storage files or a CIDv0 or CIDv1 for ipfs files. The content is checked
+// against the hash as it is read: the last Read returns ErrItemHashMismatch
+// instead of io.EOF when it does not match.
+func (client *TwentySixClient) OpenFile(hash string) (io.ReadCloser, error) {
+	return client.OpenFileWithContext(context.Background(), hash)
+}
+
+func (client *TwentySixClient) OpenFileWithContext(ctx context.Context, hash string) (io.ReadCloser, error) {
+	itemType, err := fileItemType(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := client.openRaw(ctx, hash, itemType, 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifyingReader{body: body, hasher: newFileHasher(itemType, hash), hash: hash}, nil
+}
+
+// OpenFileRange streams length bytes of the file addressed by hash starting
+// at offset, up to the end of the file when length is negative. A part of a
+// file cannot be checked against its hash.
+func (client *TwentySixClient) OpenFileRange(hash string, offset int64, length int64) (io.ReadCloser, error) {
+	return client.OpenFileRangeWithContext(context.Background(), hash, offset, length)
+}
+
+func (client *TwentySixClient) OpenFileRangeWithContext(ctx context.Context, hash string, offset int64, length int64) (io.ReadCloser, error) {
+	itemType, err := fileItemType(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.openRaw(ctx, hash, itemType, offset, length)
+}
+
+// DownloadFile copies the file addressed by hash to writer and checks it
+// against the hash. Interrupted transfers are resumed with range requests
+// according to the client retry policy. The content is written as it comes:
+// on ErrItemHashMismatch the writer already received it.
+func (client *TwentySixClient) DownloadFile(hash string, writer io.Writer) (int64, error) {
+	return client.DownloadFileWithContext(context.Background(), hash, writer)
+}
+
+func (client *TwentySixClient) DownloadFileWithContext(ctx context.Context, hash string, writer io.Writer) (int64, error) {
+	itemType, err := fileItemType(hash)
+	if err != nil {
+		return 0, err
+	}
+
+	hasher := newFileHasher(itemType, hash)
+
+	written, err := client.download(ctx, hash, itemType, 0, io.MultiWriter(writer, hasher))
+	if err != nil {
+		return written, err
+	}
+
+	return written, checkFileHash(hash, hasher)
+}
+
+// DownloadFileToPath downloads the file addressed by hash to path. The
+// content goes to path + ".part" first, which is renamed to path once
+// checked: a download interrupted for good is resumed from the part file by
+// the next call.
+func (client *TwentySixClient) DownloadFileToPath(hash string, path string) error {
+	return client.DownloadFileToPathWithContext(context.Background(), hash, path)
+}
+
+func (client *TwentySixClient) DownloadFileToPathWithContext(ctx context.Context, hash string, path string) error {
+	itemType, err := fileItemType(hash)
+	if err != nil {
+		return err
+	}
+
+	partPath := path + ".part"
+
+	file, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	hasher := newFileHasher(itemType, hash)
+
+	offset, err := io.Copy(hasher, file)
+	if err == nil {
+		_, err = client.download(ctx, hash, itemType, offset, io.MultiWriter(file, hasher))
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := checkFileHash(hash, hasher); err != nil {
+		os.Remove(partPath)
+		return err
+	}
+
+	return os.Rename(partPath, path)
+}
+
+// download copies the file from offset to writer, requesting the rest of the
+// file again when the transfer is interrupted. Errors of writer are returned
+// as is: what it received is unknown.
+func (client *TwentySixClient) download(ctx context.Context, hash string, itemType MessageItemType, offset int64, writer io.Writer) (int64, error) {
+	var written int64
+
+	for attempt := 1; ; attempt++ {
+		body, err := client.openRaw(ctx, hash, itemType, offset+written, -1)
+		if err != nil {
+			return written, err
+		}
+
+		reader := &bodyReader{body: body}
+		n, err := io.Copy(writer, reader)
+		body.Close()
+		written += n
+
+		if err == nil {
+			return written, nil
+		}
+
+		if reader.err == nil {
+			return written, err
+		}
+
+		if ctx.Err() != nil {
+			return written, ctx.Err()
+		}
+
+		if attempt >= client.retryPolicy.MaxAttempts {
+			return written, err
+		}
+
+		if err := sleepContext(ctx, client.retryPolicy.backoff(attempt, nil)); err != nil {
+			return written, err
+		}
+	}
+}
+
+// bodyReader records the errors of a response body to tell them apart from
+// the errors of the writer it is copied to.
+type bodyReader struct {
+	body io.Reader
+	err  error
+}
+
+func (reader *bodyReader) Read(data []byte) (int, error) {
+	n, err := reader.body.Read(data)
+	if err != nil && err != io.EOF {
+		reader.err = err
+	}
+
+	return n, err
+}
+
+func checkFileHash(hash string, hasher fileHasher) error {
+	computed, err := hasher.Sum()
+	if err != nil {
+		return err
+	}
+
+	if computed != hash && !sameCID(computed, hash) {
+		return fmt.Errorf("%w: file %s has hash %s", ErrItemHashMismatch, hash, computed)
+	}
+
+	return nil
+}
+
+type verifyingReader struct {
+	body   io.ReadCloser
+	hasher fileHasher
+	hash   string
+}
+
+func (reader *verifyingReader) Read(data []byte) (int, error) {
+	n, err := reader.body.Read(data)
+	if _, hashErr := reader.hasher.Write(data[:n]); hashErr != nil {
+		return n, hashErr
+	}
+
+	if err == io.EOF {
+		if err := checkFileHash(reader.hash, reader.hasher); err != nil {
+			return n, err
+		}
+	}
+
+	return n, err
+}
+
+func (reader *verifyingReader) Close() error {
+	return reader.body.Close()
+}
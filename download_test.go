@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDownloadFile(t *testing.T) {
+
+	content := bytes.Repeat([]byte("twentysix"), 10000)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var interrupted atomic.Bool
+	var ranges []string
+
+	client, server := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/storage/raw/" + hash:
+			ranges = append(ranges, r.Header.Get("Range"))
+
+			// Drop the first transfer halfway.
+			if r.Header.Get("Range") == "" && interrupted.CompareAndSwap(false, true) {
+				w.Header().Set("Content-Length", "90000")
+				w.Write(content[:1000])
+				w.(http.Flusher).Flush()
+				panic(http.ErrAbortHandler)
+			}
+
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		case "/ipfs/QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o", "/ipfs/bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4":
+			w.Write([]byte("hello world\n"))
+		case "/api/v0/storage/raw/" + strings.Repeat("0", 64):
+			w.Write([]byte("tampered"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	WithIpfsGatewayUrl(server.URL)(&client)
+	ctx := context.Background()
+
+	var downloaded bytes.Buffer
+	written, err := client.DownloadFileWithContext(ctx, hash, &downloaded)
+	if err != nil || written != int64(len(content)) || !bytes.Equal(downloaded.Bytes(), content) {
+		t.Fatalf(`Interrupted download not resumed: %d bytes, %v`, written, err)
+	}
+
+	if len(ranges) != 2 || ranges[1] != "bytes=1000-" {
+		t.Fatalf(`Unexpected range requests %q`, ranges)
+	}
+
+	part, err := client.OpenFileRangeWithContext(ctx, hash, 9, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer part.Close()
+
+	if data, _ := io.ReadAll(part); string(data) != "twentysix" {
+		t.Fatalf(`Unexpected range content %q`, data)
+	}
+
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path+".part", content[:5000], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.DownloadFileToPathWithContext(ctx, hash, path); err != nil {
+		t.Fatalf(`DownloadFileToPath failed: %v`, err)
+	}
+
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, content) || ranges[len(ranges)-1] != "bytes=5000-" {
+		t.Fatalf(`Download not resumed from the part file`)
+	}
+
+	file, err := client.OpenFileWithContext(ctx, "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if data, err := io.ReadAll(file); err != nil || string(data) != "hello world\n" {
+		t.Fatalf(`Unexpected ipfs content %q: %v`, data, err)
+	}
+
+	var raw bytes.Buffer
+	if _, err := client.DownloadFileWithContext(ctx, "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4", &raw); err != nil || raw.String() != "hello world\n" {
+		t.Fatalf(`Unexpected CIDv1 content %q: %v`, raw.String(), err)
+	}
+
+	tampered, err := client.OpenFileWithContext(ctx, strings.Repeat("0", 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tampered.Close()
+
+	if _, err := io.ReadAll(tampered); !errors.Is(err, ErrItemHashMismatch) {
+		t.Fatalf(`Expected ErrItemHashMismatch, got %v`, err)
+	}
+
+	if _, err := client.OpenFileWithContext(ctx, "not-a-hash"); !errors.Is(err, ErrUnsupportedHash) {
+		t.Fatalf(`Expected ErrUnsupportedHash, got %v`, err)
+	}
+}
+
+// failingWriter accepts limit bytes then fails.
+type failingWriter struct {
+	limit int
+}
+
+func (writer *failingWriter) Write(data []byte) (int, error) {
+	if len(data) > writer.limit {
+		n := writer.limit
+		writer.limit = 0
+		return n, errDiskFull
+	}
+
+	writer.limit -= len(data)
+	return len(data), nil
+}
+
+var errDiskFull = errors.New("disk full")
+
+func TestDownloadFileWriterError(t *testing.T) {
+
+	content := bytes.Repeat([]byte("twentysix"), 10000)
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	var requests atomic.Int32
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+
+	written, err := client.DownloadFile(hash, &failingWriter{limit: 1000})
+	if !errors.Is(err, errDiskFull) || written != 1000 {
+		t.Fatalf(`Expected the writer error after 1000 bytes, got %d bytes: %v`, written, err)
+	}
+
+	if requests.Load() != 1 {
+		t.Fatalf(`Writer error should not be retried, got %d requests`, requests.Load())
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrMessageNotFound = errors.New("message not found")
+	ErrRejected        = errors.New("message rejected")
+	ErrTimeout         = errors.New("timeout")
+	ErrForgotten       = errors.New("message forgotten")
+)
+
+// RejectionError carries the reason given by the node for rejecting a
+// message. It matches ErrRejected with errors.Is.
+type RejectionError struct {
+	ItemHash  string
+	ErrorCode int
+	Details   interface{}
+}
+
+func (e *RejectionError) Error() string {
+	if e.Details == nil {
+		return fmt.Sprintf("message %s rejected with error code %d", e.ItemHash, e.ErrorCode)
+	}
+
+	details, _ := json.Marshal(e.Details)
+	return fmt.Sprintf("message %s rejected with error code %d: %s", e.ItemHash, e.ErrorCode, details)
+}
+
+func (e *RejectionError) Is(target error) bool {
+	return target == ErrRejected
+}
+
+// APIError is returned when a node answers with an error status. It matches
+// ErrNotFound, ErrRejected and ErrTimeout with errors.Is depending on the
+// status code.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Endpoint   string
+	Detail     string
+}
+
+func (e *APIError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	}
+
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode), e.Detail)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRejected:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrTimeout:
+		return e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusGatewayTimeout
+	}
+
+	return false
+}
+
+// readResponse reads and closes the response body, returning an APIError
+// when the node did not answer with a 2xx status.
+func readResponse(response *http.Response) ([]byte, error) {
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		apiErr := &APIError{
+			StatusCode: response.StatusCode,
+			Detail:     errorDetail(body),
+		}
+
+		if response.Request != nil {
+			apiErr.Method = response.Request.Method
+			apiErr.Endpoint = response.Request.URL.Redacted()
+		}
+
+		return body, apiErr
+	}
+
+	return body, nil
+}
+
+// errorDetail extracts a readable message from a node error body, which is
+// either JSON ({"detail": ...}, {"error": ...}, a publication status) or
+// plain text.
+func errorDetail(body []byte) string {
+	var parsed struct {
+		Detail            interface{}      `json:"detail"`
+		Error             interface{}      `json:"error"`
+		Message           interface{}      `json:"message"`
+		PublicationStatus *json.RawMessage `json:"publication_status"`
+	}
+
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		for _, detail := range []interface{}{parsed.Detail, parsed.Error, parsed.Message} {
+			switch value := detail.(type) {
+			case nil:
+				continue
+			case string:
+				return value
+			default:
+				encoded, _ := json.Marshal(value)
+				return string(encoded)
+			}
+		}
+
+		if parsed.PublicationStatus != nil {
+			return "publication status " + string(*parsed.PublicationStatus)
+		}
+	}
+
+	detail := strings.TrimSpace(string(body))
+	if len(detail) > 512 {
+		detail = detail[:512] + "..."
+	}
+
+	return detail
+}
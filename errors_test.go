@@ -0,0 +1,43 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestAPIErrors(t *testing.T) {
+
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/messages":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"detail":"invalid signature"}`))
+		case "/api/v0/messages.json":
+			w.Write([]byte(`{"messages":[],"pagination_page":1,"pagination_per_page":20,"pagination_total":0}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`404: Not Found`))
+		}
+	}), WithRetryPolicy(NoRetryPolicy))
+
+	_, _, err := client.SendMessage(PostMessageType, map[string]string{"Hello": "World"}, 1)
+	if !errors.Is(err, ErrRejected) {
+		t.Fatalf(`Rejected message not reported: %v`, err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Detail != "invalid signature" {
+		t.Fatalf(`Bad API error: %v`, err)
+	}
+
+	var result interface{}
+	err = client.GetAggregate(client.Address(), "missing", &result)
+	if !errors.Is(err, ErrAggregateNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf(`Missing aggregate not reported: %v`, err)
+	}
+
+	if _, err := client.GetMessageByHash("missing"); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf(`Missing message not reported: %v`, err)
+	}
+}
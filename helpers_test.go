@@ -0,0 +1,33 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testPrivateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+func newTestAccount(t *testing.T) TwentySixAccount {
+	t.Helper()
+
+	acc, err := NewTwentySixAccountFromPrivateKey(testPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return acc
+}
+
+// newTestClient serves handler from a test node closed at the end of the
+// test and returns a client signing with the test account and talking to
+// that node, followed by options.
+func newTestClient(t *testing.T, handler http.Handler, options ...ClientOption) (TwentySixClient, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	options = append([]ClientOption{WithApiUrl(server.URL)}, options...)
+	return NewTwentySixClientWithOptions(newTestAccount(t), options...), server
+}
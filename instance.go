@@ -1,52 +1,53 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
-	"io"
 	"net/http"
 	"time"
 )
 
 func (client *TwentySixClient) CreateInstance(instance InstanceMessageContent) (Message, MessageResponse, error) {
+	return client.CreateInstanceWithContext(context.Background(), instance)
+}
+
+func (client *TwentySixClient) CreateInstanceWithContext(ctx context.Context, instance InstanceMessageContent) (Message, MessageResponse, error) {
 	now := float64(time.Now().UnixMilli()) / 1000
 
 	instanceMessage := instance
 	instanceMessage.Time = now
-	instanceMessage.Address = client.account.Address
+	instanceMessage.Address = client.Address()
 
-	message, res, err := client.SendMessage(InstanceMessageType, instanceMessage, now)
+	message, res, _, err := client.publish(ctx, InstanceMessageType, instanceMessage, now)
 	if err != nil {
 		return Message{}, MessageResponse{}, err
 	}
 
-	var createInstanceResponse MessageResponse
-	if err := json.Unmarshal(res, &createInstanceResponse); err != nil {
-		return Message{}, MessageResponse{}, err
-	}
-
-	return message, createInstanceResponse, nil
+	return message, res, nil
 }
 
 func (client *TwentySixClient) GetInstanceState(hash string) (SchedulerAllocation, error) {
-	body := &bytes.Buffer{}
-	endpoint := "https://scheduler.api.aleph.sh/api/v0/allocation/" + hash
+	return client.GetInstanceStateWithContext(context.Background(), hash)
+}
+
+func (client *TwentySixClient) GetInstanceStateWithContext(ctx context.Context, hash string) (SchedulerAllocation, error) {
+	endpoint := client.schedulerUrl + "/api/v0/allocation/" + hash
 
 	var res SchedulerAllocation
 
-	request, err := http.NewRequest("GET", endpoint, body)
-	if err != nil {
-		return res, err
-	}
+	response, err := client.retry(ctx, func() (*http.Response, error) {
+		request, err := client.newRequest(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
 
-	request.Header.Add("Accept", "application/json")
-	response, err := client.http.Do(request)
+		return client.http.Do(request)
+	})
 	if err != nil {
 		return res, err
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
+	resultBody, err := readResponse(response)
 	if err != nil {
 		return res, err
 	}
@@ -59,34 +60,17 @@ func (client *TwentySixClient) GetInstanceState(hash string) (SchedulerAllocatio
 }
 
 func (client *TwentySixClient) GetInstanceMessages(size uint64, page uint64) ([]Message, uint64, error) {
-	return client.GetMessages(size, page, []string{}, []string{client.account.Address}, []string{client.channel}, []MessageType{InstanceMessageType})
+	return client.GetInstanceMessagesWithContext(context.Background(), size, page)
 }
 
-func (client *TwentySixClient) GetInstanceMessageByItemHash(hash string) (Message, error) {
-	var page uint64 = 1
-	var parsingEnded = false
-
-	for !parsingEnded {
-		volumes, remainingItems, err := client.GetInstanceMessages(50, page)
-		if err != nil {
-			return Message{}, err
-		}
-
-		for i := 0; i < len(volumes); i++ {
-			var itemContent StoreMessageContent
-			json.Unmarshal([]byte(volumes[i].ItemContent), &itemContent)
-
-			if itemContent.ItemHash == hash {
-				return volumes[i], nil
-			}
-		}
+func (client *TwentySixClient) GetInstanceMessagesWithContext(ctx context.Context, size uint64, page uint64) ([]Message, uint64, error) {
+	return client.GetMessagesWithContext(ctx, size, page, nil, []string{client.Address()}, []string{client.channel}, []MessageType{InstanceMessageType})
+}
 
-		if remainingItems > 0 {
-			page += 1
-		} else {
-			parsingEnded = true
-		}
-	}
+func (client *TwentySixClient) GetInstanceMessageByItemHash(hash string) (Message, error) {
+	return client.GetInstanceMessageByItemHashWithContext(context.Background(), hash)
+}
 
-	return Message{}, errors.New("instance message not found")
+func (client *TwentySixClient) GetInstanceMessageByItemHashWithContext(ctx context.Context, hash string) (Message, error) {
+	return client.findOwnMessage(ctx, InstanceMessageType, hash)
 }
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultPageSize is the number of messages fetched per page by iterators.
+const DefaultPageSize = 50
+
+// MessageIterator walks the messages matching a query, fetching pages from
+// the node on demand. Stop at any time by no longer calling Next.
+//
+//	it := client.IterateMessages(ctx, 0, nil, nil, []string{"TEST"}, nil)
+//	for it.Next() {
+//		message := it.Message()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type MessageIterator struct {
+	client   *TwentySixClient
+	ctx      context.Context
+	params   url.Values
+	pageSize uint64
+
+	page    uint64
+	buffer  []Message
+	current Message
+	total   uint64
+	fetched bool
+	last    bool
+	err     error
+}
+
+// IterateMessages returns an iterator over the messages matching the
+// filters, the same ones GetMessages accepts. A page size of 0 uses
+// DefaultPageSize.
+func (client *TwentySixClient) IterateMessages(ctx context.Context, pageSize uint64, hashes []string, addresses []string, channels []string, msgTypes []MessageType) *MessageIterator {
+	return client.IterateQuery(ctx, messageQuery(hashes, addresses, channels, msgTypes), pageSize)
+}
+
+// Next advances to the next message, fetching the following page when the
+// current one is exhausted. It returns false at the end of the results or on
+// error. The content of storage and ipfs messages is only fetched when
+// decoded: failing to fetch it does not stop the iteration.
+func (it *MessageIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		if it.err != nil || it.last {
+			return false
+		}
+
+		it.fetch()
+	}
+
+	it.current = it.buffer[0]
+	it.buffer = it.buffer[1:]
+
+	return true
+}
+
+// Message returns the message Next advanced to.
+func (it *MessageIterator) Message() Message {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+// Total returns the number of messages matching the query, as reported by
+// the node. The first page is fetched if it was not yet.
+func (it *MessageIterator) Total() (uint64, error) {
+	if !it.fetched && it.err == nil {
+		it.fetch()
+	}
+
+	return it.total, it.err
+}
+
+func (it *MessageIterator) fetch() {
+	page, err := it.client.getMessagePage(it.ctx, it.params, it.pageSize, it.page+1)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page++
+	it.fetched = true
+	it.total = page.PaginationTotal
+	it.buffer = page.Messages
+	it.last = remainingMessages(page, it.pageSize) == 0
+}
+
+func (client *TwentySixClient) getMessagePage(ctx context.Context, filters url.Values, size uint64, page uint64) (GetMessageResponse, error) {
+	params := url.Values{}
+	for key, values := range filters {
+		params[key] = values
+	}
+
+	params.Set("page", fmt.Sprint(page))
+	params.Set("size", fmt.Sprint(size))
+
+	response, err := client.doApi(ctx, "GET", "/api/v0/messages.json?"+params.Encode(), nil, "")
+	if err != nil {
+		return GetMessageResponse{}, err
+	}
+
+	body, err := readResponse(response)
+	if err != nil {
+		return GetMessageResponse{}, err
+	}
+
+	var result GetMessageResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return GetMessageResponse{}, err
+	}
+
+	for i := range result.Messages {
+		result.Messages[i] = client.lazyContent(result.Messages[i])
+	}
+
+	return result, nil
+}
+
+// remainingMessages returns how many messages follow the given page,
+// computed from the pagination the node reports since it may serve less
+// messages per page than requested. Without pagination fields a short page
+// is the last one, and a full page is assumed to be followed by another one.
+func remainingMessages(page GetMessageResponse, size uint64) uint64 {
+	if len(page.Messages) == 0 {
+		return 0
+	}
+
+	if page.PaginationPage == 0 || page.PaginationPerPage == 0 {
+		if uint64(len(page.Messages)) < size {
+			return 0
+		}
+		return size
+	}
+
+	seen := page.PaginationPage * page.PaginationPerPage
+	if seen >= page.PaginationTotal {
+		return 0
+	}
+
+	return page.PaginationTotal - seen
+}
+
+// ownMessages iterates the messages of the given type sent by the client
+// address on its channel.
+func (client *TwentySixClient) ownMessages(ctx context.Context, msgType MessageType) *MessageIterator {
+	return client.IterateQuery(ctx, client.ownQuery(msgType), DefaultPageSize)
+}
+
+func (client *TwentySixClient) ownQuery(msgType MessageType) MessageQuery {
+	return MessageQuery{
+		Addresses: []string{client.Address()},
+		Channels:  []string{client.channel},
+		Types:     []MessageType{msgType},
+	}
+}
+
+// findOwnMessage returns the latest message of the given type sent by the
+// client whose item hash, or for STORE messages the hash of the stored file,
+// is hash. The node does the filtering.
+func (client *TwentySixClient) findOwnMessage(ctx context.Context, msgType MessageType, hash string, statuses ...MessageStatus) (Message, error) {
+	byHash := client.ownQuery(msgType)
+	byHash.Hashes = []string{hash}
+	queries := []MessageQuery{byHash}
+
+	if msgType == StoreMessageType {
+		byContent := client.ownQuery(msgType)
+		byContent.ContentHashes = []string{hash}
+		queries = []MessageQuery{byContent, byHash}
+	}
+
+	for _, query := range queries {
+		query.Statuses = statuses
+		query.SortOrder = DescendingSortOrder
+
+		it := client.IterateQuery(ctx, query, DefaultPageSize)
+		for it.Next() {
+			if message := it.Message(); matchesItemHash(message, hash) {
+				return message, nil
+			}
+		}
+
+		if err := it.Err(); err != nil {
+			return Message{}, err
+		}
+	}
+
+	return Message{}, fmt.Errorf("%w: %s message %s", ErrMessageNotFound, strings.ToLower(string(msgType)), hash)
+}
+
+func matchesItemHash(message Message, hash string) bool {
+	if message.ItemHash == hash {
+		return true
+	}
+
+	content, err := message.Content()
+	store, ok := content.(*StoreMessageContent)
+
+	return err == nil && ok && store.ItemHash == hash
+}
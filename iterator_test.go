@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestMessagesHandler(total int, requests *atomic.Int32) http.Handler {
+	return newCappedMessagesHandler(total, 0, requests)
+}
+
+// newCappedMessagesHandler serves at most maxSize messages per page whatever
+// the requested size, when maxSize is not 0.
+func newCappedMessagesHandler(total int, maxSize int, requests *atomic.Int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if maxSize > 0 && size > maxSize {
+			size = maxSize
+		}
+
+		response := GetMessageResponse{
+			PaginationPage:    uint64(page),
+			PaginationPerPage: uint64(size),
+			PaginationTotal:   uint64(total),
+			Messages:          []Message{},
+		}
+
+		for i := (page - 1) * size; i < page*size && i < total; i++ {
+			response.Messages = append(response.Messages, Message{ItemHash: fmt.Sprint("hash-", i), Type: PostMessageType})
+		}
+
+		json.NewEncoder(w).Encode(response)
+	})
+}
+
+func TestMessageIterator(t *testing.T) {
+
+	var requests atomic.Int32
+	client, _ := newTestClient(t, newTestMessagesHandler(5, &requests))
+
+	it := client.IterateMessages(context.Background(), 2, nil, nil, nil, nil)
+
+	total, err := it.Total()
+	if err != nil || total != 5 {
+		t.Fatalf(`Unexpected total %d: %v`, total, err)
+	}
+
+	count := 0
+	for it.Next() {
+		if it.Message().ItemHash != fmt.Sprint("hash-", count) {
+			t.Fatalf(`Unexpected message %s at position %d`, it.Message().ItemHash, count)
+		}
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatalf(`Iteration failed: %v`, err)
+	}
+
+	if count != 5 || requests.Load() != 3 {
+		t.Fatalf(`Expected 5 messages in 3 requests, got %d in %d`, count, requests.Load())
+	}
+}
+
+func TestMessageIteratorEarlyStop(t *testing.T) {
+
+	var requests atomic.Int32
+	client, _ := newTestClient(t, newTestMessagesHandler(100, &requests))
+
+	message, err := client.GetPostMessageByItemHash("hash-3")
+	if err != nil || message.ItemHash != "hash-3" {
+		t.Fatalf(`Message not found: %v`, err)
+	}
+
+	if requests.Load() != 1 {
+		t.Fatalf(`Expected a single page to be fetched, got %d`, requests.Load())
+	}
+
+	_, err = client.GetPostMessageByItemHash("unknown")
+	if !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf(`Expected ErrMessageNotFound, got %v`, err)
+	}
+}
+
+func TestMessageIteratorCappedPageSize(t *testing.T) {
+
+	var requests atomic.Int32
+	client, _ := newTestClient(t, newCappedMessagesHandler(45, 20, &requests))
+
+	count := 0
+	it := client.IterateMessages(context.Background(), 50, nil, nil, nil, nil)
+	for it.Next() {
+		count++
+	}
+
+	if err := it.Err(); err != nil || count != 45 || requests.Load() != 3 {
+		t.Fatalf(`Expected 45 messages in 3 requests, got %d in %d: %v`, count, requests.Load(), err)
+	}
+
+	if remaining := remainingMessages(GetMessageResponse{Messages: make([]Message, 3)}, 5); remaining != 0 {
+		t.Fatalf(`Short page without pagination should be the last one, %d remaining`, remaining)
+	}
+}
+
+func TestMessageIteratorLazyContent(t *testing.T) {
+
+	content := []byte(`{"type":"test","content":"stored"}`)
+	hash := sha256.Sum256(content)
+	stored := Message{ItemHash: hex.EncodeToString(hash[:]), Type: PostMessageType, ItemType: StorageMessageItem}
+	missing := Message{ItemHash: strings.Repeat("0", 64), Type: PostMessageType, ItemType: StorageMessageItem}
+
+	var fetches atomic.Int32
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/storage/raw/" + stored.ItemHash:
+			fetches.Add(1)
+			w.Write(content)
+		case "/api/v0/messages.json":
+			json.NewEncoder(w).Encode(GetMessageResponse{Messages: []Message{missing, stored}, PaginationPage: 1, PaginationPerPage: 2, PaginationTotal: 2})
+		default:
+			fetches.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	var messages []Message
+	it := client.IterateMessages(context.Background(), 2, nil, nil, nil, nil)
+	for it.Next() {
+		messages = append(messages, it.Message())
+	}
+
+	if err := it.Err(); err != nil || len(messages) != 2 || fetches.Load() != 0 {
+		t.Fatalf(`Iteration fetched %d contents, %d messages: %v`, fetches.Load(), len(messages), err)
+	}
+
+	if _, err := messages[0].Content(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf(`Expected ErrNotFound for the missing content, got %v`, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		payload, err := DecodePayload[string](messages[1])
+		if err != nil || payload != "stored" {
+			t.Fatalf(`Unexpected payload %q: %v`, payload, err)
+		}
+	}
+
+	if fetches.Load() != 2 {
+		t.Fatalf(`Content should be fetched once per message, got %d fetches`, fetches.Load())
+	}
+}
@@ -0,0 +1,242 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Pbkdf2Iterations is the iteration count used by geth and most wallets for
+// pbkdf2 encrypted keystores.
+const Pbkdf2Iterations = 262144
+
+// KeystoreOptions selects the key derivation function used to encrypt a
+// keystore file and its cost parameters.
+type KeystoreOptions struct {
+	KDF              KeystoreKDF
+	ScryptN          int
+	ScryptP          int
+	Pbkdf2Iterations int
+}
+
+// StandardKeystoreOptions are the geth defaults (scrypt, 256MB of memory).
+var StandardKeystoreOptions = KeystoreOptions{
+	KDF:     ScryptKeystoreKDF,
+	ScryptN: keystore.StandardScryptN,
+	ScryptP: keystore.StandardScryptP,
+}
+
+// LightKeystoreOptions trade security for speed (scrypt, 4MB of memory).
+var LightKeystoreOptions = KeystoreOptions{
+	KDF:     ScryptKeystoreKDF,
+	ScryptN: keystore.LightScryptN,
+	ScryptP: keystore.LightScryptP,
+}
+
+type pbkdf2KeystoreJSON struct {
+	Address string             `json:"address"`
+	Crypto  pbkdf2KeystoreData `json:"crypto"`
+	Id      string             `json:"id"`
+	Version int                `json:"version"`
+}
+
+type pbkdf2KeystoreData struct {
+	Cipher       string `json:"cipher"`
+	CipherText   string `json:"ciphertext"`
+	CipherParams struct {
+		IV string `json:"iv"`
+	} `json:"cipherparams"`
+	KDF       KeystoreKDF `json:"kdf"`
+	KDFParams struct {
+		C     int    `json:"c"`
+		DKLen int    `json:"dklen"`
+		PRF   string `json:"prf"`
+		Salt  string `json:"salt"`
+	} `json:"kdfparams"`
+	MAC string `json:"mac"`
+}
+
+func NewTwentySixAccountFromKeystore(keystorePath string, passphrase string) (TwentySixAccount, error) {
+	keyJson, err := os.ReadFile(keystorePath)
+	if err != nil {
+		return TwentySixAccount{}, err
+	}
+
+	return NewTwentySixAccountFromKeystoreJSON(keyJson, passphrase)
+}
+
+// NewTwentySixAccountFromKeystoreJSON decrypts a Web3 Secret Storage (v3)
+// document, encrypted with either scrypt or pbkdf2.
+func NewTwentySixAccountFromKeystoreJSON(keyJson []byte, passphrase string) (TwentySixAccount, error) {
+	key, err := keystore.DecryptKey(keyJson, passphrase)
+	if err != nil {
+		return TwentySixAccount{}, err
+	}
+
+	return newTwentySixAccount(key.PrivateKey)
+}
+
+// NewKeystoreAccount generates a random account and saves it encrypted in
+// keystorePath. The file must not exist yet.
+func NewKeystoreAccount(keystorePath string, passphrase string, options KeystoreOptions) (TwentySixAccount, error) {
+	if _, err := os.Stat(keystorePath); err == nil {
+		return TwentySixAccount{}, errors.New("keystore file already exists")
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return TwentySixAccount{}, err
+	}
+
+	account, err := newTwentySixAccount(privateKey)
+	if err != nil {
+		return TwentySixAccount{}, err
+	}
+
+	if err := account.SaveKeystore(keystorePath, passphrase, options); err != nil {
+		return TwentySixAccount{}, err
+	}
+
+	return account, nil
+}
+
+// ChangeKeystorePassphrase re-encrypts the keystore file in place with a new
+// passphrase. The file is only replaced once the new content is fully written.
+func ChangeKeystorePassphrase(keystorePath string, oldPassphrase string, newPassphrase string, options KeystoreOptions) error {
+	account, err := NewTwentySixAccountFromKeystore(keystorePath, oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	return account.SaveKeystore(keystorePath, newPassphrase, options)
+}
+
+func (account TwentySixAccount) SaveKeystore(keystorePath string, passphrase string, options KeystoreOptions) error {
+	keyJson, err := account.EncryptKeystore(passphrase, options)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(keystorePath, keyJson)
+}
+
+// EncryptKeystore returns the account private key as a Web3 Secret Storage
+// (v3) JSON document.
+func (account TwentySixAccount) EncryptKeystore(passphrase string, options KeystoreOptions) ([]byte, error) {
+	if account.PrivateKey == nil {
+		return nil, errors.New("account has no private key")
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	switch options.KDF {
+	case ScryptKeystoreKDF, "":
+		scryptN, scryptP := options.ScryptN, options.ScryptP
+		if scryptN == 0 || scryptP == 0 {
+			scryptN, scryptP = keystore.StandardScryptN, keystore.StandardScryptP
+		}
+
+		key := &keystore.Key{
+			Id:         id,
+			Address:    crypto.PubkeyToAddress(account.PrivateKey.PublicKey),
+			PrivateKey: account.PrivateKey,
+		}
+
+		return keystore.EncryptKey(key, passphrase, scryptN, scryptP)
+	case Pbkdf2KeystoreKDF:
+		iterations := options.Pbkdf2Iterations
+		if iterations == 0 {
+			iterations = Pbkdf2Iterations
+		}
+
+		data, err := encryptPbkdf2(crypto.FromECDSA(account.PrivateKey), []byte(passphrase), iterations)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(pbkdf2KeystoreJSON{
+			Address: hex.EncodeToString(crypto.PubkeyToAddress(account.PrivateKey.PublicKey).Bytes()),
+			Crypto:  data,
+			Id:      id.String(),
+			Version: 3,
+		})
+	default:
+		return nil, errors.New("unsupported keystore kdf: " + string(options.KDF))
+	}
+}
+
+func encryptPbkdf2(data []byte, auth []byte, iterations int) (pbkdf2KeystoreData, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return pbkdf2KeystoreData{}, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return pbkdf2KeystoreData{}, err
+	}
+
+	derivedKey := pbkdf2.Key(auth, salt, iterations, 32, sha256.New)
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return pbkdf2KeystoreData{}, err
+	}
+
+	cipherText := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, data)
+
+	mac := crypto.Keccak256(derivedKey[16:32], cipherText)
+
+	var result pbkdf2KeystoreData
+	result.Cipher = "aes-128-ctr"
+	result.CipherText = hex.EncodeToString(cipherText)
+	result.CipherParams.IV = hex.EncodeToString(iv)
+	result.KDF = Pbkdf2KeystoreKDF
+	result.KDFParams.C = iterations
+	result.KDFParams.DKLen = 32
+	result.KDFParams.PRF = "hmac-sha256"
+	result.KDFParams.Salt = hex.EncodeToString(salt)
+	result.MAC = hex.EncodeToString(mac)
+
+	return result, nil
+}
+
+func writeFileAtomic(path string, content []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+
+	acc, err := NewTwentySixAccountFromPrivateKey("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatalf(`NewTwentySixAccountFromPrivateKey failed to be instanciated: %v`, err)
+	}
+
+	options := []KeystoreOptions{
+		LightKeystoreOptions,
+		{KDF: Pbkdf2KeystoreKDF, Pbkdf2Iterations: 1024},
+	}
+
+	for _, option := range options {
+		keyJson, err := acc.EncryptKeystore("passphrase", option)
+		if err != nil {
+			t.Fatalf(`EncryptKeystore failed with %s: %v`, option.KDF, err)
+		}
+
+		decrypted, err := NewTwentySixAccountFromKeystoreJSON(keyJson, "passphrase")
+		if err != nil {
+			t.Fatalf(`NewTwentySixAccountFromKeystoreJSON failed with %s: %v`, option.KDF, err)
+		}
+
+		if decrypted.Address != acc.Address {
+			t.Fatalf(`Bad address decrypted with %s`, option.KDF)
+		}
+
+		if _, err := NewTwentySixAccountFromKeystoreJSON(keyJson, "wrong"); err == nil {
+			t.Fatalf(`Keystore decrypted with a wrong passphrase using %s`, option.KDF)
+		}
+	}
+}
+
+func TestKeystoreChangePassphrase(t *testing.T) {
+
+	keystorePath := filepath.Join(t.TempDir(), "account.json")
+
+	acc, err := NewKeystoreAccount(keystorePath, "old", LightKeystoreOptions)
+	if err != nil {
+		t.Fatalf(`NewKeystoreAccount failed: %v`, err)
+	}
+
+	if _, err := NewKeystoreAccount(keystorePath, "old", LightKeystoreOptions); err == nil {
+		t.Fatalf(`NewKeystoreAccount overwrote an existing keystore`)
+	}
+
+	if err := ChangeKeystorePassphrase(keystorePath, "old", "new", LightKeystoreOptions); err != nil {
+		t.Fatalf(`ChangeKeystorePassphrase failed: %v`, err)
+	}
+
+	if _, err := NewTwentySixAccountFromKeystore(keystorePath, "old"); err == nil {
+		t.Fatalf(`Keystore still decrypts with the old passphrase`)
+	}
+
+	loaded, err := NewTwentySixAccountFromKeystore(keystorePath, "new")
+	if err != nil {
+		t.Fatalf(`NewTwentySixAccountFromKeystore failed: %v`, err)
+	}
+
+	if loaded.Address != acc.Address {
+		t.Fatalf(`Bad address loaded from keystore`)
+	}
+}
@@ -1,14 +1,11 @@
 package client
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-
-	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type MessageConfirmation struct {
@@ -27,10 +24,13 @@ type Message struct {
 
 	ItemHash    string          `json:"item_hash"`
 	ItemType    MessageItemType `json:"item_type"`
-	ItemContent string          `json:"item_content"`
+	ItemContent string          `json:"item_content,omitempty"`
 
 	Confirmations []MessageConfirmation `json:"confirmations,omitempty"`
 	Confirmed     bool                  `json:"confirmed,omitempty"`
+
+	// fetcher fetches storage and ipfs content when it is first decoded.
+	fetcher *contentFetcher
 }
 
 func (msg Message) GetVerificationPayload() []byte {
@@ -40,17 +40,26 @@ func (msg Message) GetVerificationPayload() []byte {
 	return []byte(fmt.Sprintf("%s\n%s\n%s\n%s", msg.Chain, msg.Sender, msg.Type, msg.ItemHash))
 }
 
-func (msg *Message) SignMessage(account TwentySixAccount) error {
-	messageHash := accounts.TextHash(msg.GetVerificationPayload())
+func (msg *Message) SignMessage(signer Signer) error {
+	return msg.SignMessageWithContext(context.Background(), signer)
+}
+
+// SignMessageWithContext signs the message, passing ctx along when signer is
+// a ContextSigner.
+func (msg *Message) SignMessageWithContext(ctx context.Context, signer Signer) error {
+	var signature string
+	var err error
 
-	signature, err := crypto.Sign(messageHash, account.PrivateKey)
+	if contextSigner, ok := signer.(ContextSigner); ok {
+		signature, err = contextSigner.SignWithContext(ctx, msg.GetVerificationPayload())
+	} else {
+		signature, err = signer.Sign(msg.GetVerificationPayload())
+	}
 	if err != nil {
 		return err
 	}
 
-	signature[crypto.RecoveryIDOffset] += 27
-
-	msg.Signature = hexutil.Encode(signature)
+	msg.Signature = signature
 	return nil
 }
 
@@ -63,7 +72,14 @@ func (msg *Message) JSON() []byte {
 	return payload
 }
 
-func PrepareMessage(account TwentySixAccount, channel string, msgType MessageType, content interface{}, at float64) (Message, error) {
+// PrepareMessage signs a message carrying content inline. Clients upload
+// content above their inline limit instead, see
+// TwentySixClient.PrepareMessage.
+func PrepareMessage(signer Signer, channel string, msgType MessageType, content interface{}, at float64) (Message, error) {
+	return PrepareMessageWithContext(context.Background(), signer, channel, msgType, content, at)
+}
+
+func PrepareMessageWithContext(ctx context.Context, signer Signer, channel string, msgType MessageType, content interface{}, at float64) (Message, error) {
 	msgContent, err := json.Marshal(content)
 	if err != nil {
 		return Message{}, err
@@ -71,19 +87,25 @@ func PrepareMessage(account TwentySixAccount, channel string, msgType MessageTyp
 
 	contentHash := sha256.Sum256(msgContent)
 
+	return signMessage(ctx, signer, channel, msgType, at, InlineMessageItem, hex.EncodeToString(contentHash[:]), string(msgContent))
+}
+
+func signMessage(ctx context.Context, signer Signer, channel string, msgType MessageType, at float64, itemType MessageItemType, itemHash string, itemContent string) (Message, error) {
 	message := Message{
 		Type:    msgType,
-		Chain:   EthereumChain,
-		Sender:  account.Address,
+		Chain:   signer.GetChain(),
+		Sender:  signer.GetAddress(),
 		Time:    at,
 		Channel: channel,
 
-		ItemHash:    hex.EncodeToString(contentHash[:]),
-		ItemType:    InlineMessageItem,
-		ItemContent: string(msgContent),
+		ItemHash:    itemHash,
+		ItemType:    itemType,
+		ItemContent: itemContent,
 	}
 
-	message.SignMessage(account)
+	if err := message.SignMessageWithContext(ctx, signer); err != nil {
+		return Message{}, err
+	}
 
 	return message, nil
 }
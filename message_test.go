@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 	"time"
 
@@ -53,3 +54,92 @@ func TestPrepareMessage(t *testing.T) {
 		t.Fatal("ECRecover failed")
 	}
 }
+
+type staticSigner struct {
+	address string
+	chain   MessageChain
+	payload []byte
+}
+
+func (signer *staticSigner) GetAddress() string {
+	return signer.address
+}
+
+func (signer *staticSigner) GetChain() MessageChain {
+	return signer.chain
+}
+
+func (signer *staticSigner) Sign(payload []byte) (string, error) {
+	signer.payload = payload
+	return "static-signature", nil
+}
+
+func TestPrepareMessageWithCustomSigner(t *testing.T) {
+
+	signer := &staticSigner{address: "custom-address", chain: "CUSTOM"}
+
+	message, err := PrepareMessage(signer, "TEST", PostMessageType, map[string]string{"Hello": "World"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Sender != "custom-address" || message.Chain != "CUSTOM" {
+		t.Fatalf(`Message sender and chain must come from the signer`)
+	}
+	if message.Signature != "static-signature" {
+		t.Fatalf(`Message signature must come from the signer`)
+	}
+	if !bytes.Equal(signer.payload, message.GetVerificationPayload()) {
+		t.Fatalf(`Signer did not receive the verification payload`)
+	}
+}
+
+func TestMessageContent(t *testing.T) {
+
+	post, err := PrepareMessage(&staticSigner{}, "TEST", PostMessageType, PostMessageContent{
+		Type:    "order",
+		Address: "0xabc",
+		Content: map[string]interface{}{"amount": 3, "region": "eu"},
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := post.Content()
+	if err != nil {
+		t.Fatalf(`Content failed: %v`, err)
+	}
+
+	postContent, ok := content.(*PostMessageContent)
+	if !ok || postContent.Type != "order" || postContent.Address != "0xabc" {
+		t.Fatalf(`Unexpected content %#v`, content)
+	}
+
+	type order struct {
+		Amount int    `json:"amount"`
+		Region string `json:"region"`
+	}
+
+	payload, err := DecodePayload[order](post)
+	if err != nil || payload.Amount != 3 || payload.Region != "eu" {
+		t.Fatalf(`Unexpected payload %#v: %v`, payload, err)
+	}
+
+	store, err := PrepareMessage(&staticSigner{}, "TEST", StoreMessageType, StoreMessageContent{ItemHash: "abc", ItemType: StorageMessageItem}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storeContent, err := DecodeContent[StoreMessageContent](store)
+	if err != nil || storeContent.ItemHash != "abc" {
+		t.Fatalf(`Unexpected store content %#v: %v`, storeContent, err)
+	}
+
+	if _, err := DecodePayload[order](store); !errors.Is(err, ErrNoPayload) {
+		t.Fatalf(`Expected ErrNoPayload, got %v`, err)
+	}
+
+	if _, err := (Message{Type: "UNKNOWN"}).Content(); !errors.Is(err, ErrUnknownMessageType) {
+		t.Fatalf(`Expected ErrUnknownMessageType, got %v`, err)
+	}
+}
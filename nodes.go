@@ -0,0 +1,324 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHealthCheckInterval is how long node health probes are trusted
+// before nodes are probed again.
+const DefaultHealthCheckInterval = time.Minute
+
+// NodeStatus is the last known state of an API node.
+type NodeStatus struct {
+	Url       string
+	Healthy   bool
+	Latency   time.Duration
+	CheckedAt time.Time
+}
+
+// nodePool keeps the API nodes of a client ordered by preference. It is
+// shared by the copies of a client so health information is not lost.
+type nodePool struct {
+	mutex    sync.Mutex
+	nodes    []NodeStatus
+	interval time.Duration
+}
+
+func newNodePool(urls []string) *nodePool {
+	pool := &nodePool{interval: DefaultHealthCheckInterval}
+	for _, url := range urls {
+		pool.nodes = append(pool.nodes, NodeStatus{Url: url, Healthy: true})
+	}
+
+	return pool
+}
+
+func (pool *nodePool) statuses() []NodeStatus {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	return append([]NodeStatus{}, pool.nodes...)
+}
+
+// stale reports whether nodes must be probed before choosing one. A single
+// node is never probed: there is nothing to choose from.
+func (pool *nodePool) stale() bool {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if len(pool.nodes) < 2 {
+		return false
+	}
+
+	for _, node := range pool.nodes {
+		if time.Since(node.CheckedAt) > pool.interval {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ordered returns node urls, healthy nodes first sorted by latency, then
+// unhealthy nodes as a last resort.
+func (pool *nodePool) ordered() []string {
+	nodes := pool.statuses()
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Healthy != nodes[j].Healthy {
+			return nodes[i].Healthy
+		}
+		return nodes[i].Latency < nodes[j].Latency
+	})
+
+	urls := make([]string, len(nodes))
+	for i, node := range nodes {
+		urls[i] = node.Url
+	}
+
+	return urls
+}
+
+func (pool *nodePool) update(url string, healthy bool, latency time.Duration, checked bool) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	for i := range pool.nodes {
+		if pool.nodes[i].Url != url {
+			continue
+		}
+
+		pool.nodes[i].Healthy = healthy
+		if checked {
+			pool.nodes[i].Latency = latency
+			pool.nodes[i].CheckedAt = time.Now()
+		}
+	}
+}
+
+// Nodes returns the last known state of the client API nodes.
+func (client *TwentySixClient) Nodes() []NodeStatus {
+	return client.nodes.statuses()
+}
+
+// CheckNodes probes every API node concurrently and records whether it
+// answers and how fast. Reads are then routed to the fastest healthy node.
+func (client *TwentySixClient) CheckNodes(ctx context.Context) []NodeStatus {
+	var wg sync.WaitGroup
+
+	for _, node := range client.nodes.statuses() {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+
+			start := time.Now()
+			healthy := client.probeNode(ctx, url)
+			if ctx.Err() != nil {
+				return
+			}
+
+			client.nodes.update(url, healthy, time.Since(start), true)
+		}(node.Url)
+	}
+
+	wg.Wait()
+
+	return client.nodes.statuses()
+}
+
+func (client *TwentySixClient) probeNode(ctx context.Context, url string) bool {
+	request, err := client.newRequest(ctx, "GET", url+"/api/v0/version", nil)
+	if err != nil {
+		return false
+	}
+
+	response, err := client.http.Do(request)
+	if err != nil {
+		return false
+	}
+
+	defer response.Body.Close()
+
+	return response.StatusCode == http.StatusOK
+}
+
+// failover reports whether a request should be retried on another node.
+func failover(response *http.Response, err error) bool {
+	var reader *readerError
+	if errors.As(err, &reader) {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	return response.StatusCode >= http.StatusInternalServerError
+}
+
+// doApi sends a request to the API nodes in order of preference, failing
+// over to the next node on network and server errors, and retries the whole
+// round according to the client retry policy.
+func (client *TwentySixClient) doApi(ctx context.Context, method string, path string, body []byte, contentType string) (*http.Response, error) {
+	return client.doApiHeader(ctx, method, path, body, contentTypeHeader(contentType))
+}
+
+// doApiHeader is doApi with arbitrary request headers.
+func (client *TwentySixClient) doApiHeader(ctx context.Context, method string, path string, body []byte, header http.Header) (*http.Response, error) {
+	return client.retry(ctx, func() (*http.Response, error) {
+		return client.doApiOnce(ctx, method, path, body, header)
+	})
+}
+
+func (client *TwentySixClient) doApiOnce(ctx context.Context, method string, path string, body []byte, header http.Header) (*http.Response, error) {
+	if client.nodes.stale() {
+		client.CheckNodes(ctx)
+	}
+
+	urls := client.nodes.ordered()
+	if len(urls) == 0 {
+		return nil, errors.New("no api node configured")
+	}
+
+	var response *http.Response
+	var err error
+
+	for i, url := range urls {
+		response, err = client.sendApi(ctx, method, url+path, body, header)
+		if !failover(response, err) {
+			return response, err
+		}
+
+		client.nodes.update(url, false, 0, false)
+
+		if i < len(urls)-1 && response != nil {
+			response.Body.Close()
+		}
+	}
+
+	return response, err
+}
+
+// broadcastApi posts body to the broadcast count first nodes concurrently
+// and returns the response of the preferred node that accepted it. With a
+// broadcast count of one it behaves as doApi.
+func (client *TwentySixClient) broadcastApi(ctx context.Context, path string, body []byte, contentType string) (*http.Response, error) {
+	if client.broadcast <= 1 {
+		return client.doApi(ctx, "POST", path, body, contentType)
+	}
+
+	return client.retry(ctx, func() (*http.Response, error) {
+		return client.broadcastApiOnce(ctx, path, body, contentTypeHeader(contentType))
+	})
+}
+
+func (client *TwentySixClient) broadcastApiOnce(ctx context.Context, path string, body []byte, header http.Header) (*http.Response, error) {
+	if client.nodes.stale() {
+		client.CheckNodes(ctx)
+	}
+
+	urls := client.nodes.ordered()
+	if len(urls) == 0 {
+		return nil, errors.New("no api node configured")
+	}
+
+	if len(urls) > client.broadcast {
+		urls = urls[:client.broadcast]
+	}
+
+	responses := make([]*http.Response, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+
+			responses[i], errs[i] = client.sendApi(ctx, "POST", url+path, body, header)
+			if failover(responses[i], errs[i]) {
+				client.nodes.update(url, false, 0, false)
+			}
+		}(i, url)
+	}
+
+	wg.Wait()
+
+	chosen := -1
+	for i := range urls {
+		if !failover(responses[i], errs[i]) {
+			chosen = i
+			break
+		}
+	}
+
+	if chosen == -1 {
+		chosen = len(urls) - 1
+	}
+
+	for i := range urls {
+		if i != chosen && responses[i] != nil {
+			responses[i].Body.Close()
+		}
+	}
+
+	return responses[chosen], errs[chosen]
+}
+
+// streamApi sends a request whose body can only be read once to the
+// preferred API node, without retries nor failover.
+func (client *TwentySixClient) streamApi(ctx context.Context, method string, path string, body io.Reader, contentType string) (*http.Response, error) {
+	if client.nodes.stale() {
+		client.CheckNodes(ctx)
+	}
+
+	urls := client.nodes.ordered()
+	if len(urls) == 0 {
+		return nil, errors.New("no api node configured")
+	}
+
+	response, err := client.sendStream(ctx, method, urls[0]+path, body, contentTypeHeader(contentType))
+	if failover(response, err) {
+		client.nodes.update(urls[0], false, 0, false)
+	}
+
+	return response, err
+}
+
+func (client *TwentySixClient) sendApi(ctx context.Context, method string, endpoint string, body []byte, header http.Header) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	return client.sendStream(ctx, method, endpoint, reader, header)
+}
+
+func (client *TwentySixClient) sendStream(ctx context.Context, method string, endpoint string, reader io.Reader, header http.Header) (*http.Response, error) {
+	request, err := client.newRequest(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	return client.http.Do(request)
+}
+
+func contentTypeHeader(contentType string) http.Header {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	return header
+}
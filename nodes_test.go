@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestNode(healthy bool, hits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if !healthy {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v0/version":
+			w.Write([]byte(`{"version":"test"}`))
+		case "/api/v0/messages":
+			w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+		default:
+			w.Write([]byte(`{"messages":[],"pagination_page":1,"pagination_per_page":20,"pagination_total":0}`))
+		}
+	}))
+}
+
+func TestNodeFailover(t *testing.T) {
+
+	acc := newTestAccount(t)
+
+	var downHits, upHits atomic.Int32
+	down := newTestNode(false, &downHits)
+	defer down.Close()
+	up := newTestNode(true, &upHits)
+	defer up.Close()
+
+	client := NewTwentySixClientWithOptions(acc, WithApiUrls(down.URL, up.URL))
+
+	if _, _, err := client.GetMessages(20, 1, nil, nil, nil, nil); err != nil {
+		t.Fatalf(`GetMessages did not fail over: %v`, err)
+	}
+
+	statuses := client.Nodes()
+	if statuses[0].Healthy || !statuses[1].Healthy {
+		t.Fatalf(`Node health not recorded: %+v`, statuses)
+	}
+
+	downBefore := downHits.Load()
+	if _, _, err := client.GetMessages(20, 1, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if downHits.Load() != downBefore {
+		t.Fatalf(`Unhealthy node was preferred over a healthy one`)
+	}
+}
+
+func TestNodeBroadcast(t *testing.T) {
+
+	acc := newTestAccount(t)
+
+	var firstHits, secondHits atomic.Int32
+	first := newTestNode(true, &firstHits)
+	defer first.Close()
+	second := newTestNode(true, &secondHits)
+	defer second.Close()
+
+	client := NewTwentySixClientWithOptions(acc, WithApiUrls(first.URL, second.URL), WithBroadcast(2))
+	client.CheckNodes(context.Background())
+	firstHits.Store(0)
+	secondHits.Store(0)
+
+	if _, _, err := client.SendMessage(PostMessageType, map[string]string{"Hello": "World"}, 1); err != nil {
+		t.Fatalf(`SendMessage failed: %v`, err)
+	}
+
+	if firstHits.Load() != 1 || secondHits.Load() != 1 {
+		t.Fatalf(`Message was not broadcast to both nodes`)
+	}
+}
+
+func TestNodeFailoverOnRequestError(t *testing.T) {
+
+	// The flaky node passes the health probe but fails the request itself.
+	var flakyHits atomic.Int32
+	client, flaky := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/version" {
+			w.Write([]byte(`{"version":"test"}`))
+			return
+		}
+
+		flakyHits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}), WithRetryPolicy(NoRetryPolicy))
+
+	var upHits atomic.Int32
+	up := newTestNode(true, &upHits)
+	defer up.Close()
+
+	WithApiUrls(flaky.URL, up.URL)(&client)
+	client.CheckNodes(context.Background())
+
+	// Make the flaky node the preferred one whatever the probed latencies.
+	client.nodes.update(flaky.URL, true, time.Millisecond, true)
+	client.nodes.update(up.URL, true, time.Second, true)
+	upHits.Store(0)
+
+	if _, _, err := client.GetMessages(20, 1, nil, nil, nil, nil); err != nil {
+		t.Fatalf(`GetMessages did not fail over: %v`, err)
+	}
+
+	if flakyHits.Load() != 1 || upHits.Load() != 1 {
+		t.Fatalf(`Request was not sent to the preferred node then the next one: %d, %d`, flakyHits.Load(), upHits.Load())
+	}
+
+	statuses := client.Nodes()
+	if statuses[0].Healthy || !statuses[1].Healthy {
+		t.Fatalf(`Failing node not marked unhealthy: %+v`, statuses)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientOption configures a TwentySixClient built by
+// NewTwentySixClientWithOptions.
+type ClientOption func(client *TwentySixClient)
+
+// WithHTTPClient makes the client send its requests through httpClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(client *TwentySixClient) {
+		if httpClient != nil {
+			client.http = httpClient
+		}
+	}
+}
+
+// WithTransport keeps the current http.Client settings but sends requests
+// through transport.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(client *TwentySixClient) {
+		httpClient := *client.http
+		httpClient.Transport = transport
+		client.http = &httpClient
+	}
+}
+
+// WithTimeout bounds every request, including reading the response body.
+// The http.Client given to WithHTTPClient is copied, not modified.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(client *TwentySixClient) {
+		httpClient := *client.http
+		httpClient.Timeout = timeout
+		client.http = &httpClient
+	}
+}
+
+// WithApiUrl points the client to another API node, e.g. a staging node or
+// a local stand-in server. An empty url keeps the default.
+func WithApiUrl(apiUrl string) ClientOption {
+	return WithApiUrls(apiUrl)
+}
+
+// WithApiUrls spreads requests over several API nodes: reads go to the
+// fastest healthy node and fail over to the others on errors.
+func WithApiUrls(apiUrls ...string) ClientOption {
+	return func(client *TwentySixClient) {
+		urls := []string{}
+		for _, apiUrl := range apiUrls {
+			if apiUrl != "" {
+				urls = append(urls, strings.TrimSuffix(apiUrl, "/"))
+			}
+		}
+
+		if len(urls) > 0 {
+			interval := client.nodes.interval
+			client.nodes = newNodePool(urls)
+			client.nodes.interval = interval
+		}
+	}
+}
+
+// WithHealthCheckInterval sets how often API nodes are probed, see
+// DefaultHealthCheckInterval.
+func WithHealthCheckInterval(interval time.Duration) ClientOption {
+	return func(client *TwentySixClient) {
+		client.nodes.interval = interval
+	}
+}
+
+// WithBroadcast sends new messages to the count preferred API nodes at once
+// instead of a single one.
+func WithBroadcast(count int) ClientOption {
+	return func(client *TwentySixClient) {
+		client.broadcast = count
+	}
+}
+
+func WithSchedulerUrl(schedulerUrl string) ClientOption {
+	return func(client *TwentySixClient) {
+		if schedulerUrl != "" {
+			client.schedulerUrl = strings.TrimSuffix(schedulerUrl, "/")
+		}
+	}
+}
+
+// WithIpfsGatewayUrl sets the IPFS gateway the content and files of ipfs
+// items are fetched from. An empty url keeps the default.
+func WithIpfsGatewayUrl(gatewayUrl string) ClientOption {
+	return func(client *TwentySixClient) {
+		if gatewayUrl != "" {
+			client.gatewayUrl = strings.TrimSuffix(gatewayUrl, "/")
+		}
+	}
+}
+
+// WithIpfsApiUrl sets the IPFS node API directories are added through by
+// StoreDirectory, which fails with ErrNoIpfsApi without one. The node must
+// accept writes from the client.
+func WithIpfsApiUrl(ipfsApiUrl string) ClientOption {
+	return func(client *TwentySixClient) {
+		if ipfsApiUrl != "" {
+			client.ipfsApiUrl = strings.TrimSuffix(ipfsApiUrl, "/")
+		}
+	}
+}
+
+func WithUserAgent(userAgent string) ClientOption {
+	return func(client *TwentySixClient) {
+		client.userAgent = userAgent
+	}
+}
+
+// WithChannel sets the channel messages are published to and listed from.
+func WithChannel(channel string) ClientOption {
+	return func(client *TwentySixClient) {
+		client.channel = channel
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, use NoRetryPolicy to disable
+// retries.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *TwentySixClient) {
+		client.retryPolicy = policy
+	}
+}
+
+// WithInlineLimit sets the size in bytes above which message content is
+// uploaded instead of being sent inline.
+func WithInlineLimit(limit int) ClientOption {
+	return func(client *TwentySixClient) {
+		client.inlineLimit = limit
+	}
+}
+
+// WithOffchainItemType chooses where content too large to be inline is
+// uploaded: StorageMessageItem (the default) or IpfsMessageItem.
+func WithOffchainItemType(itemType MessageItemType) ClientOption {
+	return func(client *TwentySixClient) {
+		if itemType == StorageMessageItem || itemType == IpfsMessageItem {
+			client.offchainType = itemType
+		}
+	}
+}
+
+// WithStoreItemType chooses where StoreFile and StoreReader upload files:
+// StorageMessageItem (the default) or IpfsMessageItem.
+func WithStoreItemType(itemType MessageItemType) ClientOption {
+	return func(client *TwentySixClient) {
+		if itemType == StorageMessageItem || itemType == IpfsMessageItem {
+			client.storeType = itemType
+		}
+	}
+}
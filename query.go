@@ -0,0 +1,212 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidQuery = errors.New("invalid message query")
+
+// MessageQuery filters the messages returned by the node. Empty fields are
+// not filtered on; values of a same field are alternatives.
+//
+//	query := MessageQuery{
+//		Types:        []MessageType{PostMessageType},
+//		ContentTypes: []string{"order"},
+//		Tags:         []string{"eu"},
+//		StartDate:    time.Now().Add(-24 * time.Hour),
+//	}
+type MessageQuery struct {
+	Hashes    []string
+	Addresses []string
+	Channels  []string
+	Types     []MessageType
+	Chains    []MessageChain
+	Statuses  []MessageStatus
+
+	// Refs matches the ref of POST and STORE messages, ContentHashes the
+	// hash of the file of STORE messages, ContentKeys the key of AGGREGATE
+	// messages and ContentTypes the type of POST messages.
+	Refs          []string
+	ContentHashes []string
+	ContentKeys   []string
+	ContentTypes  []string
+	Tags          []string
+
+	StartDate time.Time
+	EndDate   time.Time
+
+	SortBy    MessageSortBy
+	SortOrder SortOrder
+}
+
+// Validate checks the query before it is sent to the node.
+func (query MessageQuery) Validate() error {
+	for _, msgType := range query.Types {
+		switch msgType {
+		case AggregateMessageType, ForgetMessageType, ProgramMessageType, PostMessageType, StoreMessageType, InstanceMessageType:
+		default:
+			return fmt.Errorf("%w: unknown message type %q", ErrInvalidQuery, msgType)
+		}
+	}
+
+	for _, status := range query.Statuses {
+		switch status {
+		case PendingMessageStatus, ProcessedMessageStatus, RejectedMessageStatus, ForgottenMessageStatus:
+		default:
+			return fmt.Errorf("%w: unknown message status %q", ErrInvalidQuery, status)
+		}
+	}
+
+	for _, chain := range query.Chains {
+		if chain == "" {
+			return fmt.Errorf("%w: empty chain", ErrInvalidQuery)
+		}
+	}
+
+	if len(query.ContentTypes) > 0 && !query.allows(PostMessageType) {
+		return fmt.Errorf("%w: content types only apply to %s messages", ErrInvalidQuery, PostMessageType)
+	}
+
+	if len(query.ContentKeys) > 0 && !query.allows(AggregateMessageType) {
+		return fmt.Errorf("%w: content keys only apply to %s messages", ErrInvalidQuery, AggregateMessageType)
+	}
+
+	if len(query.ContentHashes) > 0 && !query.allows(StoreMessageType) {
+		return fmt.Errorf("%w: content hashes only apply to %s messages", ErrInvalidQuery, StoreMessageType)
+	}
+
+	if len(query.Refs) > 0 && !query.allows(PostMessageType) && !query.allows(StoreMessageType) {
+		return fmt.Errorf("%w: refs only apply to %s and %s messages", ErrInvalidQuery, PostMessageType, StoreMessageType)
+	}
+
+	if !query.StartDate.IsZero() && !query.EndDate.IsZero() && query.EndDate.Before(query.StartDate) {
+		return fmt.Errorf("%w: end date %s is before start date %s", ErrInvalidQuery, query.EndDate, query.StartDate)
+	}
+
+	switch query.SortBy {
+	case "", TimeMessageSort, TxTimeMessageSort:
+	default:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidQuery, query.SortBy)
+	}
+
+	switch query.SortOrder {
+	case 0, AscendingSortOrder, DescendingSortOrder:
+	default:
+		return fmt.Errorf("%w: unknown sort order %d", ErrInvalidQuery, query.SortOrder)
+	}
+
+	return nil
+}
+
+// allows reports whether messages of the given type can match the query.
+func (query MessageQuery) allows(msgType MessageType) bool {
+	if len(query.Types) == 0 {
+		return true
+	}
+
+	for _, queried := range query.Types {
+		if queried == msgType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (query MessageQuery) values() url.Values {
+	params := url.Values{}
+
+	for _, hash := range query.Hashes {
+		params.Add("hashes", hash)
+	}
+	for _, address := range query.Addresses {
+		params.Add("addresses", address)
+	}
+	for _, channel := range query.Channels {
+		params.Add("channels", channel)
+	}
+	for _, msgType := range query.Types {
+		params.Add("msgTypes", string(msgType))
+	}
+	for _, chain := range query.Chains {
+		params.Add("chains", string(chain))
+	}
+	for _, status := range query.Statuses {
+		params.Add("msgStatuses", string(status))
+	}
+	for _, ref := range query.Refs {
+		params.Add("refs", ref)
+	}
+	for _, hash := range query.ContentHashes {
+		params.Add("contentHashes", hash)
+	}
+	for _, key := range query.ContentKeys {
+		params.Add("contentKeys", key)
+	}
+	for _, contentType := range query.ContentTypes {
+		params.Add("contentTypes", contentType)
+	}
+	for _, tag := range query.Tags {
+		params.Add("tags", tag)
+	}
+
+	if !query.StartDate.IsZero() {
+		params.Set("startDate", unixSeconds(query.StartDate))
+	}
+	if !query.EndDate.IsZero() {
+		params.Set("endDate", unixSeconds(query.EndDate))
+	}
+
+	if query.SortBy != "" {
+		params.Set("sortBy", string(query.SortBy))
+	}
+	if query.SortOrder != 0 {
+		params.Set("sortOrder", strconv.Itoa(int(query.SortOrder)))
+	}
+
+	return params
+}
+
+func unixSeconds(at time.Time) string {
+	return strconv.FormatFloat(float64(at.UnixMilli())/1000, 'f', -1, 64)
+}
+
+func (client *TwentySixClient) QueryMessages(query MessageQuery, size uint64, page uint64) ([]Message, uint64, error) {
+	return client.QueryMessagesWithContext(context.Background(), query, size, page)
+}
+
+// QueryMessagesWithContext returns a page of the messages matching query and
+// the number of messages remaining after it.
+func (client *TwentySixClient) QueryMessagesWithContext(ctx context.Context, query MessageQuery, size uint64, page uint64) ([]Message, uint64, error) {
+	if err := query.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	result, err := client.getMessagePage(ctx, query.values(), size, page)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result.Messages, remainingMessages(result, size), nil
+}
+
+// IterateQuery returns an iterator over the messages matching query. An
+// invalid query is reported by the iterator Err method.
+func (client *TwentySixClient) IterateQuery(ctx context.Context, query MessageQuery, pageSize uint64) *MessageIterator {
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+
+	return &MessageIterator{
+		client:   client,
+		ctx:      ctx,
+		params:   query.values(),
+		pageSize: pageSize,
+		err:      query.Validate(),
+	}
+}
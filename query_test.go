@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestMessageQueryValidate(t *testing.T) {
+
+	invalid := []MessageQuery{
+		{Types: []MessageType{"UNKNOWN"}},
+		{Statuses: []MessageStatus{"done"}},
+		{Types: []MessageType{StoreMessageType}, ContentTypes: []string{"order"}},
+		{Types: []MessageType{PostMessageType}, ContentKeys: []string{"profile"}},
+		{StartDate: time.Now(), EndDate: time.Now().Add(-time.Hour)},
+		{SortOrder: 2},
+	}
+
+	for i, query := range invalid {
+		if err := query.Validate(); !errors.Is(err, ErrInvalidQuery) {
+			t.Fatalf(`Query %d should be invalid, got %v`, i, err)
+		}
+	}
+
+	if err := (MessageQuery{}).Validate(); err != nil {
+		t.Fatalf(`Empty query should be valid: %v`, err)
+	}
+}
+
+func TestQueryMessages(t *testing.T) {
+
+	var received url.Values
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Query()
+		json.NewEncoder(w).Encode(GetMessageResponse{Messages: []Message{}, PaginationPage: 1, PaginationPerPage: 20})
+	}))
+
+	since := time.UnixMilli(1700000000500)
+	_, _, err := client.QueryMessagesWithContext(context.Background(), MessageQuery{
+		Types:        []MessageType{PostMessageType},
+		ContentTypes: []string{"order"},
+		Tags:         []string{"eu"},
+		StartDate:    since,
+		SortOrder:    DescendingSortOrder,
+	}, 20, 1)
+	if err != nil {
+		t.Fatalf(`QueryMessages failed: %v`, err)
+	}
+
+	expected := map[string]string{
+		"msgTypes":     "POST",
+		"contentTypes": "order",
+		"tags":         "eu",
+		"startDate":    "1700000000.5",
+		"sortOrder":    "-1",
+		"page":         "1",
+		"size":         "20",
+	}
+
+	for key, value := range expected {
+		if received.Get(key) != value {
+			t.Fatalf(`Expected %s=%s, got %q`, key, value, received.Get(key))
+		}
+	}
+
+	_, _, err = client.QueryMessages(MessageQuery{SortBy: "size"}, 20, 1)
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf(`Expected ErrInvalidQuery, got %v`, err)
+	}
+}
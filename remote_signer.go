@@ -0,0 +1,291 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DefaultRemoteSignerTimeout bounds each call to the signing service.
+const DefaultRemoteSignerTimeout = 30 * time.Second
+
+const (
+	PersonalSignMethod = "personal_sign"
+	EthSignMethod      = "eth_sign"
+	EthAccountsMethod  = "eth_accounts"
+)
+
+type jsonRpcRequest struct {
+	JsonRpc string        `json:"jsonrpc"`
+	Id      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonRpcResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	Id      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonRpcError   `json:"error,omitempty"`
+}
+
+// RemoteSigner forwards verification payloads to a JSON-RPC signing service
+// (personal_sign or eth_sign) so the private key never lives in the client
+// process. The service must return the signature exactly as it has to appear
+// in the message.
+type RemoteSigner struct {
+	endpoint string
+	address  string
+	chain    MessageChain
+	method   string
+	timeout  time.Duration
+	http     *http.Client
+	nextId   atomic.Uint64
+}
+
+// RemoteSignerOption configures a RemoteSigner.
+type RemoteSignerOption func(signer *RemoteSigner)
+
+// WithSignerHTTPClient sends the requests to the signing service with
+// httpClient, for instance one authenticating the client.
+func WithSignerHTTPClient(httpClient *http.Client) RemoteSignerOption {
+	return func(signer *RemoteSigner) {
+		if httpClient != nil {
+			signer.http = httpClient
+		}
+	}
+}
+
+// WithSignerTimeout bounds each call to the signing service, 0 disables the
+// bound.
+func WithSignerTimeout(timeout time.Duration) RemoteSignerOption {
+	return func(signer *RemoteSigner) {
+		signer.timeout = timeout
+	}
+}
+
+func NewRemoteSigner(endpoint string, address string, chain MessageChain, options ...RemoteSignerOption) *RemoteSigner {
+	signer := &RemoteSigner{
+		endpoint: endpoint,
+		address:  address,
+		chain:    chain,
+		method:   PersonalSignMethod,
+		timeout:  DefaultRemoteSignerTimeout,
+		http:     &http.Client{},
+	}
+
+	for _, option := range options {
+		option(signer)
+	}
+
+	return signer
+}
+
+// NewRemoteSignerFromEndpoint asks the signing service for its first account
+// using eth_accounts.
+func NewRemoteSignerFromEndpoint(endpoint string, chain MessageChain, options ...RemoteSignerOption) (*RemoteSigner, error) {
+	return NewRemoteSignerFromEndpointWithContext(context.Background(), endpoint, chain, options...)
+}
+
+func NewRemoteSignerFromEndpointWithContext(ctx context.Context, endpoint string, chain MessageChain, options ...RemoteSignerOption) (*RemoteSigner, error) {
+	signer := NewRemoteSigner(endpoint, "", chain, options...)
+
+	var addresses []string
+	if err := signer.call(ctx, EthAccountsMethod, []interface{}{}, &addresses); err != nil {
+		return nil, err
+	}
+
+	if len(addresses) == 0 {
+		return nil, errors.New("remote signer has no account")
+	}
+
+	signer.address = addresses[0]
+	return signer, nil
+}
+
+// SetMethod selects the JSON-RPC method used to sign, PersonalSignMethod by
+// default.
+func (signer *RemoteSigner) SetMethod(method string) {
+	signer.method = method
+}
+
+func (signer *RemoteSigner) GetAddress() string {
+	return signer.address
+}
+
+func (signer *RemoteSigner) GetChain() MessageChain {
+	return signer.chain
+}
+
+func (signer *RemoteSigner) Sign(payload []byte) (string, error) {
+	return signer.SignWithContext(context.Background(), payload)
+}
+
+func (signer *RemoteSigner) SignWithContext(ctx context.Context, payload []byte) (string, error) {
+	var params []interface{}
+	switch signer.method {
+	case EthSignMethod:
+		params = []interface{}{signer.address, hexutil.Encode(payload)}
+	default:
+		params = []interface{}{hexutil.Encode(payload), signer.address}
+	}
+
+	var signature string
+	if err := signer.call(ctx, signer.method, params, &signature); err != nil {
+		return "", err
+	}
+
+	if signature == "" {
+		return "", errors.New("remote signer returned an empty signature")
+	}
+
+	return signature, nil
+}
+
+func (signer *RemoteSigner) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	if signer.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, signer.timeout)
+		defer cancel()
+	}
+
+	req := jsonRpcRequest{
+		JsonRpc: "2.0",
+		Id:      signer.nextId.Add(1),
+		Method:  method,
+		Params:  params,
+	}
+
+	buff, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", signer.endpoint, bytes.NewBuffer(buff))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", "application/json")
+
+	response, err := signer.http.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	resultBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	var rpcResponse jsonRpcResponse
+	if err := json.Unmarshal(resultBody, &rpcResponse); err != nil {
+		return fmt.Errorf("remote signer returned status %d: %w", response.StatusCode, err)
+	}
+
+	if rpcResponse.Error != nil {
+		return fmt.Errorf("remote signer error %d: %s", rpcResponse.Error.Code, rpcResponse.Error.Message)
+	}
+
+	return json.Unmarshal(rpcResponse.Result, result)
+}
+
+// SigningServer is a reference JSON-RPC signing service backed by any local
+// Signer. It answers eth_accounts, personal_sign and eth_sign and is meant to
+// be run next to a RemoteSigner, for instance behind httptest in tests.
+type SigningServer struct {
+	signer Signer
+}
+
+func NewSigningServer(signer Signer) *SigningServer {
+	return &SigningServer{signer: signer}
+}
+
+func (server *SigningServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req jsonRpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		server.reply(w, jsonRpcResponse{JsonRpc: "2.0", Error: &jsonRpcError{Code: -32700, Message: "parse error"}})
+		return
+	}
+
+	response := jsonRpcResponse{JsonRpc: "2.0", Id: req.Id}
+
+	result, rpcErr := server.handle(req)
+	if rpcErr != nil {
+		response.Error = rpcErr
+	} else {
+		response.Result, _ = json.Marshal(result)
+	}
+
+	server.reply(w, response)
+}
+
+func (server *SigningServer) handle(req jsonRpcRequest) (interface{}, *jsonRpcError) {
+	var data, address interface{}
+
+	switch req.Method {
+	case EthAccountsMethod:
+		return []string{server.signer.GetAddress()}, nil
+	case PersonalSignMethod:
+		if len(req.Params) < 2 {
+			return nil, &jsonRpcError{Code: -32602, Message: "personal_sign expects data and address"}
+		}
+		data, address = req.Params[0], req.Params[1]
+	case EthSignMethod:
+		if len(req.Params) < 2 {
+			return nil, &jsonRpcError{Code: -32602, Message: "eth_sign expects address and data"}
+		}
+		address, data = req.Params[0], req.Params[1]
+	default:
+		return nil, &jsonRpcError{Code: -32601, Message: "method not found"}
+	}
+
+	addressStr, ok := address.(string)
+	if !ok || !strings.EqualFold(addressStr, server.signer.GetAddress()) {
+		return nil, &jsonRpcError{Code: -32602, Message: "unknown account"}
+	}
+
+	dataStr, ok := data.(string)
+	if !ok {
+		return nil, &jsonRpcError{Code: -32602, Message: "invalid data"}
+	}
+
+	payload, err := hexutil.Decode(dataStr)
+	if err != nil {
+		return nil, &jsonRpcError{Code: -32602, Message: "invalid data: " + err.Error()}
+	}
+
+	signature, err := server.signer.Sign(payload)
+	if err != nil {
+		return nil, &jsonRpcError{Code: -32000, Message: err.Error()}
+	}
+
+	return signature, nil
+}
+
+func (server *SigningServer) reply(w http.ResponseWriter, response jsonRpcResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRemoteSigner(t *testing.T) {
+
+	acc, err := NewTwentySixAccountFromPrivateKey("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatalf(`NewTwentySixAccountFromPrivateKey failed to be instanciated: %v`, err)
+	}
+
+	server := httptest.NewServer(NewSigningServer(acc))
+	defer server.Close()
+
+	signer, err := NewRemoteSignerFromEndpoint(server.URL, EthereumChain)
+	if err != nil {
+		t.Fatalf(`NewRemoteSignerFromEndpoint failed: %v`, err)
+	}
+
+	if signer.GetAddress() != acc.Address {
+		t.Fatalf(`Bad address returned by eth_accounts`)
+	}
+
+	content := map[string]string{"Hello": "World"}
+
+	local, err := PrepareMessage(acc, "TEST", PostMessageType, content, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, method := range []string{PersonalSignMethod, EthSignMethod} {
+		signer.SetMethod(method)
+
+		remote, err := PrepareMessage(signer, "TEST", PostMessageType, content, 1)
+		if err != nil {
+			t.Fatalf(`PrepareMessage with %s failed: %v`, method, err)
+		}
+
+		if remote.Signature != local.Signature {
+			t.Fatalf(`Remote signature using %s differs from local signature`, method)
+		}
+	}
+
+	unknown := NewRemoteSigner(server.URL, "0x0000000000000000000000000000000000000000", EthereumChain)
+	if _, err := PrepareMessage(unknown, "TEST", PostMessageType, content, 1); err == nil {
+		t.Fatalf(`Remote signer signed for an unknown account`)
+	}
+}
+
+func TestRemoteSignerTimeout(t *testing.T) {
+
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	signer := NewRemoteSigner(server.URL, "0x0000000000000000000000000000000000000000", EthereumChain, WithSignerTimeout(50*time.Millisecond))
+	if _, err := signer.Sign([]byte("payload")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf(`Stalled signing service not timed out: %v`, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	signer = NewRemoteSigner(server.URL, "0x0000000000000000000000000000000000000000", EthereumChain, WithSignerHTTPClient(&http.Client{}), WithSignerTimeout(0))
+	message := Message{Chain: EthereumChain}
+	if err := message.SignMessageWithContext(ctx, signer); !errors.Is(err, context.Canceled) {
+		t.Fatalf(`Signing context not passed to the signing service: %v`, err)
+	}
+}
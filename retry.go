@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are attempted again. Network
+// errors and responses with one of RetryableStatusCodes are retried, waiting
+// InitialBackoff multiplied by Multiplier after each attempt, capped to
+// MaxBackoff and randomized by +/- Jitter (a fraction of the backoff).
+type RetryPolicy struct {
+	MaxAttempts          int
+	InitialBackoff       time.Duration
+	MaxBackoff           time.Duration
+	Multiplier           float64
+	Jitter               float64
+	RetryableStatusCodes []int
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	RetryableStatusCodes: []int{
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// NoRetryPolicy makes a single attempt per request.
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+func (policy RetryPolicy) retryable(response *http.Response, err error) bool {
+	if err != nil {
+		return failover(response, err)
+	}
+
+	for _, code := range policy.RetryableStatusCodes {
+		if response.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// backoff returns the wait before the attempt following attempt (1-based).
+// A Retry-After header sent by the node takes precedence, still capped to
+// MaxBackoff so that a node cannot stall the caller.
+func (policy RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds) * time.Second
+			if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+				wait = policy.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	backoff := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+
+	if policy.Jitter > 0 {
+		backoff += backoff * policy.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(backoff)
+}
+
+// retry calls send until it succeeds, fails with a non retryable error or
+// the policy runs out of attempts. send must be safe to call several times:
+// request bodies are kept as bytes so that a signed message is re-sent as is.
+func (client *TwentySixClient) retry(ctx context.Context, send func() (*http.Response, error)) (*http.Response, error) {
+	attempts := client.retryPolicy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := send()
+		if attempt >= attempts || !client.retryPolicy.retryable(response, err) {
+			return response, err
+		}
+
+		wait := client.retryPolicy.backoff(attempt, response)
+		if response != nil {
+			response.Body.Close()
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryReusesSignedMessage(t *testing.T) {
+
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+
+	var bodies []string
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if len(bodies) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+	}), WithRetryPolicy(policy))
+
+	if _, _, err := client.SendMessage(PostMessageType, map[string]string{"Hello": "World"}, 1); err != nil {
+		t.Fatalf(`SendMessage failed: %v`, err)
+	}
+
+	if len(bodies) != 3 {
+		t.Fatalf(`Expected 3 attempts, got %d`, len(bodies))
+	}
+	if bodies[0] != bodies[1] || bodies[1] != bodies[2] {
+		t.Fatalf(`Retries did not re-send the same signed message`)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i, backoff := range expected {
+		if policy.backoff(i+1, nil) != backoff {
+			t.Fatalf(`Bad backoff for attempt %d: %v`, i+1, policy.backoff(i+1, nil))
+		}
+	}
+
+	response := &http.Response{Header: http.Header{"Retry-After": []string{"3"}}}
+	if policy.backoff(1, response) != 3*time.Second {
+		t.Fatalf(`Retry-After header ignored`)
+	}
+
+	response.Header.Set("Retry-After", "3600")
+	if policy.backoff(1, response) != 5*time.Second {
+		t.Fatalf(`Retry-After not capped to MaxBackoff: %v`, policy.backoff(1, response))
+	}
+}
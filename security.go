@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SecurityAggregateKey is the aggregate key holding the authorizations of
+// an address.
+const SecurityAggregateKey = "security"
+
+// Errors returned when updating a security aggregate.
+var (
+	ErrAuthorizationNotFound = errors.New("authorization not found")
+	ErrNotOwner              = errors.New("security aggregate can only be updated by its owner")
+)
+
+// GetAuthorizations returns the addresses allowed to publish on behalf of
+// owner, as stored in its security aggregate.
+func (client *TwentySixClient) GetAuthorizations(owner string) ([]Authorization, error) {
+	return client.GetAuthorizationsWithContext(context.Background(), owner)
+}
+
+func (client *TwentySixClient) GetAuthorizationsWithContext(ctx context.Context, owner string) ([]Authorization, error) {
+	var security SecurityAggregateContent
+	if err := client.GetAggregateWithContext(ctx, owner, SecurityAggregateKey, &security); err != nil {
+		if errors.Is(err, ErrAggregateNotFound) {
+			return []Authorization{}, nil
+		}
+
+		return nil, err
+	}
+
+	return security.Authorizations, nil
+}
+
+// AddAuthorization allows authorization.Address to publish on behalf of the
+// client address. An existing authorization for the same address and chain
+// is replaced. The security aggregate can only be updated by its owner, so
+// the client must not be used OnBehalfOf another address.
+func (client *TwentySixClient) AddAuthorization(authorization Authorization) (Message, MessageResponse, error) {
+	return client.AddAuthorizationWithContext(context.Background(), authorization)
+}
+
+func (client *TwentySixClient) AddAuthorizationWithContext(ctx context.Context, authorization Authorization) (Message, MessageResponse, error) {
+	authorizations, err := client.GetAuthorizationsWithContext(ctx, client.Address())
+	if err != nil {
+		return Message{}, MessageResponse{}, err
+	}
+
+	updated := []Authorization{}
+	for _, existing := range authorizations {
+		if !sameAuthorization(existing, authorization.Address, authorization.Chain) {
+			updated = append(updated, existing)
+		}
+	}
+	updated = append(updated, authorization)
+
+	return client.setAuthorizations(ctx, updated)
+}
+
+// RevokeAuthorization removes every authorization granted to address.
+func (client *TwentySixClient) RevokeAuthorization(address string) (Message, MessageResponse, error) {
+	return client.RevokeAuthorizationWithContext(context.Background(), address)
+}
+
+func (client *TwentySixClient) RevokeAuthorizationWithContext(ctx context.Context, address string) (Message, MessageResponse, error) {
+	authorizations, err := client.GetAuthorizationsWithContext(ctx, client.Address())
+	if err != nil {
+		return Message{}, MessageResponse{}, err
+	}
+
+	updated := []Authorization{}
+	for _, existing := range authorizations {
+		if !sameAuthorization(existing, address, "") {
+			updated = append(updated, existing)
+		}
+	}
+
+	if len(updated) == len(authorizations) {
+		return Message{}, MessageResponse{}, fmt.Errorf("%w: %s", ErrAuthorizationNotFound, address)
+	}
+
+	return client.setAuthorizations(ctx, updated)
+}
+
+func (client *TwentySixClient) setAuthorizations(ctx context.Context, authorizations []Authorization) (Message, MessageResponse, error) {
+	if client.owner != "" && !strings.EqualFold(client.owner, client.signer.GetAddress()) {
+		return Message{}, MessageResponse{}, fmt.Errorf("%w: signer %s publishes for %s", ErrNotOwner, client.signer.GetAddress(), client.owner)
+	}
+
+	return client.CreateAggregateWithContext(ctx, AggregateMessageContent{
+		Key: SecurityAggregateKey,
+		Content: SecurityAggregateContent{
+			Authorizations: authorizations,
+		},
+	})
+}
+
+func sameAuthorization(authorization Authorization, address string, chain MessageChain) bool {
+	if !strings.EqualFold(authorization.Address, address) {
+		return false
+	}
+
+	return chain == "" || authorization.Chain == "" || authorization.Chain == chain
+}
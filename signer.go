@@ -0,0 +1,25 @@
+package client
+
+import "context"
+
+// Signer is implemented by anything able to sign twentysix messages on behalf
+// of an address. TwentySixAccount is the in-memory ECDSA implementation, but
+// keystores, remote signers or accounts on other chains can be plugged into
+// TwentySixClient and PrepareMessage the same way.
+type Signer interface {
+	// GetAddress returns the address used as message sender.
+	GetAddress() string
+	// GetChain returns the chain the address belongs to.
+	GetChain() MessageChain
+	// Sign signs the message verification payload and returns the signature
+	// encoded the way the network expects it for this chain.
+	Sign(payload []byte) (string, error)
+}
+
+// ContextSigner is a Signer whose signatures can be cancelled, such as one
+// calling a remote service. Messages are then signed with the context of the
+// call publishing them.
+type ContextSigner interface {
+	Signer
+	SignWithContext(ctx context.Context, payload []byte) (string, error)
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+type SolanaAccount struct {
+	PrivateKey ed25519.PrivateKey
+	PublicKey  ed25519.PublicKey
+	Address    string
+}
+
+type solanaSignature struct {
+	Signature string `json:"signature"`
+	PublicKey string `json:"publicKey"`
+}
+
+// NewSolanaAccountFromPrivateKey accepts a base58 encoded secret key, either
+// the 64 bytes keypair exported by Solana wallets or a 32 bytes seed.
+func NewSolanaAccountFromPrivateKey(privateKey string) (SolanaAccount, error) {
+	privateKeyBytes := base58.Decode(privateKey)
+	if len(privateKeyBytes) == 0 {
+		return SolanaAccount{}, errors.New("error decoding base58 private key")
+	}
+
+	return newSolanaAccount(privateKeyBytes)
+}
+
+// NewSolanaAccountFromKeypairFile loads a keypair file as written by
+// solana-keygen, a JSON array of the 64 keypair bytes.
+func NewSolanaAccountFromKeypairFile(keypairPath string) (SolanaAccount, error) {
+	content, err := os.ReadFile(keypairPath)
+	if err != nil {
+		return SolanaAccount{}, err
+	}
+
+	var values []int
+	if err := json.Unmarshal(content, &values); err != nil {
+		return SolanaAccount{}, errors.New("error parsing solana keypair file")
+	}
+
+	keypair := make([]byte, len(values))
+	for i, value := range values {
+		if value < 0 || value > 255 {
+			return SolanaAccount{}, errors.New("error parsing solana keypair file")
+		}
+		keypair[i] = byte(value)
+	}
+
+	return newSolanaAccount(keypair)
+}
+
+func newSolanaAccount(privateKeyBytes []byte) (SolanaAccount, error) {
+	var privateKey ed25519.PrivateKey
+
+	switch len(privateKeyBytes) {
+	case ed25519.SeedSize:
+		privateKey = ed25519.NewKeyFromSeed(privateKeyBytes)
+	case ed25519.PrivateKeySize:
+		privateKey = ed25519.NewKeyFromSeed(privateKeyBytes[:ed25519.SeedSize])
+		if !privateKey.Equal(ed25519.PrivateKey(privateKeyBytes)) {
+			return SolanaAccount{}, errors.New("solana keypair public key does not match secret key")
+		}
+	default:
+		return SolanaAccount{}, errors.New("invalid solana private key length")
+	}
+
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+
+	return SolanaAccount{
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		Address:    base58.Encode(publicKey),
+	}, nil
+}
+
+func (account SolanaAccount) GetAddress() string {
+	return account.Address
+}
+
+func (account SolanaAccount) GetChain() MessageChain {
+	return SolanaChain
+}
+
+// Sign returns the ed25519 signature of the payload wrapped in the
+// {signature, publicKey} JSON document expected for SOL messages.
+func (account SolanaAccount) Sign(payload []byte) (string, error) {
+	if len(account.PrivateKey) != ed25519.PrivateKeySize {
+		return "", errors.New("account has no private key")
+	}
+
+	signature, err := json.Marshal(solanaSignature{
+		Signature: base58.Encode(ed25519.Sign(account.PrivateKey, payload)),
+		PublicKey: account.Address,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(signature), nil
+}
+
+func verifySolanaSignature(sender string, signature string, payload []byte) error {
+	var sig solanaSignature
+	if err := json.Unmarshal([]byte(signature), &sig); err != nil {
+		return err
+	}
+
+	if sig.PublicKey != sender {
+		return &VerificationError{Check: ErrSenderMismatch}
+	}
+
+	publicKey := base58.Decode(sig.PublicKey)
+	if len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("bad public key length")
+	}
+
+	if !ed25519.Verify(publicKey, payload, base58.Decode(sig.Signature)) {
+		return errors.New("ed25519 verification failed")
+	}
+
+	return nil
+}
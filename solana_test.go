@@ -0,0 +1,76 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+func TestSolanaAccountFromKeypairFile(t *testing.T) {
+
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	keypair := ed25519.NewKeyFromSeed(seed)
+
+	values := make([]string, len(keypair))
+	for i, b := range keypair {
+		values[i] = fmt.Sprint(b)
+	}
+
+	keypairPath := filepath.Join(t.TempDir(), "id.json")
+	if err := os.WriteFile(keypairPath, []byte("["+strings.Join(values, ",")+"]"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	acc, err := NewSolanaAccountFromKeypairFile(keypairPath)
+	if err != nil {
+		t.Fatalf(`NewSolanaAccountFromKeypairFile failed to be instanciated: %v`, err)
+	}
+
+	fromKey, err := NewSolanaAccountFromPrivateKey(base58.Encode(keypair))
+	if err != nil {
+		t.Fatalf(`NewSolanaAccountFromPrivateKey failed to be instanciated: %v`, err)
+	}
+
+	if acc.Address != fromKey.Address || acc.Address != base58.Encode(keypair[32:]) {
+		t.Fatalf(`Bad address generated`)
+	}
+}
+
+func TestPrepareSolanaMessage(t *testing.T) {
+
+	acc, err := NewSolanaAccountFromPrivateKey(base58.Encode(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatalf(`NewSolanaAccountFromPrivateKey failed to be instanciated: %v`, err)
+	}
+
+	message, err := PrepareMessage(acc, "TEST", PostMessageType, map[string]string{"Hello": "World"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Chain != SolanaChain || message.Sender != acc.Address {
+		t.Fatalf(`Bad chain or sender in message`)
+	}
+
+	var signature solanaSignature
+	if err := json.Unmarshal([]byte(message.Signature), &signature); err != nil {
+		t.Fatalf(`Signature is not a JSON document: %v`, err)
+	}
+
+	if signature.PublicKey != acc.Address {
+		t.Fatalf(`Bad public key in signature`)
+	}
+
+	if !ed25519.Verify(acc.PublicKey, message.GetVerificationPayload(), base58.Decode(signature.Signature)) {
+		t.Fatalf(`Invalid ed25519 signature`)
+	}
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// GetMessageStatus returns the processing status of a message as known by
+// the node: pending, processed, rejected (with the rejection reason) or
+// forgotten.
+func (client *TwentySixClient) GetMessageStatus(hash string) (MessageStatusResponse, error) {
+	return client.GetMessageStatusWithContext(context.Background(), hash)
+}
+
+func (client *TwentySixClient) GetMessageStatusWithContext(ctx context.Context, hash string) (MessageStatusResponse, error) {
+	response, err := client.doApi(ctx, "GET", "/api/v0/messages/"+url.PathEscape(hash), nil, "")
+	if err != nil {
+		return MessageStatusResponse{}, err
+	}
+
+	resultBody, err := readResponse(response)
+	if errors.Is(err, ErrNotFound) {
+		return MessageStatusResponse{}, fmt.Errorf("%w: %s: %w", ErrMessageNotFound, hash, err)
+	}
+	if err != nil {
+		return MessageStatusResponse{}, err
+	}
+
+	var status MessageStatusResponse
+	if err := json.Unmarshal(resultBody, &status); err != nil {
+		return MessageStatusResponse{}, err
+	}
+
+	return status, nil
+}
+
+// WaitMessageStatus polls the message status every interval until the
+// message is processed, rejected or forgotten. A rejected message is
+// reported as a *RejectionError, a forgotten one as ErrForgotten. A message
+// unknown to the node is considered not received yet and polled again. Use
+// WaitMessageStatusWithContext to bound the wait: a deadline exceeded is
+// reported as ErrTimeout.
+func (client *TwentySixClient) WaitMessageStatus(hash string, interval time.Duration) (MessageStatusResponse, error) {
+	return client.WaitMessageStatusWithContext(context.Background(), hash, interval)
+}
+
+func (client *TwentySixClient) WaitMessageStatusWithContext(ctx context.Context, hash string, interval time.Duration) (MessageStatusResponse, error) {
+	for {
+		status, err := client.GetMessageStatusWithContext(ctx, hash)
+		if err != nil && !errors.Is(err, ErrMessageNotFound) {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return status, fmt.Errorf("message %s status: %w: %w", hash, ErrTimeout, err)
+			}
+			return status, err
+		}
+
+		if err == nil {
+			switch status.Status {
+			case ProcessedMessageStatus:
+				return status, nil
+			case RejectedMessageStatus:
+				return status, status.rejection()
+			case ForgottenMessageStatus:
+				return status, fmt.Errorf("message %s: %w", hash, ErrForgotten)
+			}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return status, fmt.Errorf("message %s status: %w", hash, ErrTimeout)
+			}
+			return status, err
+		}
+	}
+}
+
+func (status MessageStatusResponse) rejection() error {
+	return &RejectionError{
+		ItemHash:  status.ItemHash,
+		ErrorCode: status.ErrorCode,
+		Details:   status.Details,
+	}
+}
@@ -1,11 +1,12 @@
 package client
 
 import (
-	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -14,8 +15,15 @@ import (
 	"time"
 )
 
+// StoreFile uploads the file at filePath and returns its signed STORE message
+// with the file hash, as soon as the node accepted them. The message may not
+// be processed yet: use a Sync client, or WaitMessageStatus with the message
+// item hash, to wait for it.
 func (client *TwentySixClient) StoreFile(filePath string) (Message, string, error) {
-	now := float64(time.Now().UnixMilli()) / 1000
+	return client.StoreFileWithContext(context.Background(), filePath)
+}
+
+func (client *TwentySixClient) StoreFileWithContext(ctx context.Context, filePath string) (Message, string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return Message{}, "", err
@@ -23,141 +31,337 @@ func (client *TwentySixClient) StoreFile(filePath string) (Message, string, erro
 
 	defer file.Close()
 
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
+	info, err := file.Stat()
+	if err != nil {
 		return Message{}, "", err
 	}
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	return client.StoreReaderWithContext(ctx, filepath.Base(file.Name()), file, info.Size())
+}
 
-	//Generate metadata
-	metadatapart, err := writer.CreateFormField("metadata")
-	if err != nil {
-		return Message{}, "", err
-	}
+// ProgressFunc is called as an upload goes with the number of bytes sent so
+// far and the total size, -1 when unknown.
+type ProgressFunc func(sent int64, total int64)
+
+// StoreReader uploads size bytes read from reader under name and returns
+// the signed STORE message with the file hash. Use a size of -1 when it is
+// not known in advance. Files go to the node storage unless the client was
+// configured with WithStoreItemType(IpfsMessageItem).
+//
+// The multipart body is streamed: the file is hashed while it is sent and
+// the message, which needs the hash, is sent after it. When reader is an
+// io.Seeker, failed uploads are retried according to the client retry
+// policy, each attempt going to the preferred healthy node after seeking
+// back to where reader was, and re-sending the message signed by the first
+// attempt. Other readers can only be sent once: their upload is neither
+// retried nor failed over to another node.
+func (client *TwentySixClient) StoreReader(name string, reader io.Reader, size int64) (Message, string, error) {
+	return client.StoreReaderWithContext(context.Background(), name, reader, size)
+}
 
-	itemContent := StoreMessageContent{
-		Address:  client.account.Address,
-		Time:     now,
-		ItemHash: hex.EncodeToString(hash.Sum(nil)),
-		ItemType: StorageMessageItem,
+func (client *TwentySixClient) StoreReaderWithContext(ctx context.Context, name string, reader io.Reader, size int64) (Message, string, error) {
+	if client.storeType == IpfsMessageItem {
+		return client.storeIpfsReader(ctx, name, reader, size)
 	}
 
-	jsonItem, err := json.Marshal(itemContent)
+	var message Message
+	uploaded, err := client.uploadStream(ctx, "/api/v0/storage/add_file", reader, func(writer *multipart.Writer) error {
+		signed, err := client.writeStoreBody(ctx, writer, name, reader, size, message)
+		if err != nil {
+			return err
+		}
+
+		message = signed
+		return nil
+	})
 	if err != nil {
 		return Message{}, "", err
 	}
 
-	contentHash := sha256.Sum256(jsonItem)
+	content, err := DecodeContent[StoreMessageContent](message)
+	if err != nil {
+		return Message{}, "", err
+	}
 
-	message := Message{
-		Chain:       EthereumChain,
-		Sender:      client.account.Address,
-		Channel:     client.channel,
-		Time:        now,
-		Type:        StoreMessageType,
-		ItemType:    InlineMessageItem,
-		ItemHash:    hex.EncodeToString(contentHash[:]),
-		ItemContent: string(jsonItem),
+	if uploaded.Hash != content.ItemHash {
+		return Message{}, "", fmt.Errorf("%w: file stored as %s, expected %s", ErrItemHashMismatch, uploaded.Hash, content.ItemHash)
 	}
 
-	message.SignMessage(client.account)
+	return message, content.ItemHash, nil
+}
 
-	req := BroadcastRequest{
-		Message: message,
-		Sync:    false,
-	}
+// storeIpfsReader adds the file to IPFS through the node, computing its CID
+// locally to check the one returned, then publishes its STORE message with
+// the CID of the DAG the node built.
+func (client *TwentySixClient) storeIpfsReader(ctx context.Context, name string, reader io.Reader, size int64) (Message, string, error) {
+	var hasher *cidHasher
+	uploaded, err := client.uploadStream(ctx, "/api/v0/ipfs/add_file", reader, func(writer *multipart.Writer) error {
+		// The node may add the file as CIDv0 or CIDv1, which differ by more
+		// than their encoding: both DAGs are built.
+		hasher = newCidHasher("")
+		if err := client.writeFilePart(writer, name, reader, size, hasher); err != nil {
+			return err
+		}
 
-	jsonReq, err := json.Marshal(req)
+		if _, err := hasher.sums(); err != nil {
+			return err
+		}
+
+		return writer.Close()
+	})
 	if err != nil {
 		return Message{}, "", err
 	}
 
-	metadata := bytes.NewReader(jsonReq)
-	io.Copy(metadatapart, metadata)
-
-	//Upload file
-	filepart, err := writer.CreateFormFile("file", filepath.Base(file.Name()))
+	cid, matched, err := hasher.match(uploaded.Hash)
 	if err != nil {
 		return Message{}, "", err
 	}
 
-	file, err = os.Open(filePath)
+	if !matched {
+		return Message{}, "", fmt.Errorf("%w: file added to ipfs as %s, expected %s", ErrItemHashMismatch, uploaded.Hash, cid)
+	}
+
+	now := float64(time.Now().UnixMilli()) / 1000
+
+	message, _, _, err := client.publish(ctx, StoreMessageType, StoreMessageContent{
+		Address:  client.Address(),
+		Time:     now,
+		ItemHash: cid,
+		ItemType: IpfsMessageItem,
+	}, now)
 	if err != nil {
 		return Message{}, "", err
 	}
 
-	defer file.Close()
+	return message, cid, nil
+}
 
-	io.Copy(filepart, file)
-	writer.Close()
+// uploadStream posts the multipart body produced by write as it is written
+// and decodes the node answer. write reads the file from reader: when reader
+// is an io.Seeker, the upload is retried according to the client retry
+// policy, seeking reader back to where it was before each attempt.
+func (client *TwentySixClient) uploadStream(ctx context.Context, path string, reader io.Reader, write func(writer *multipart.Writer) error) (StoreIPFSFileResponse, error) {
+	send := func(body io.Reader, contentType string) (*http.Response, error) {
+		return client.streamApi(ctx, "POST", path, body, contentType)
+	}
+
+	var response *http.Response
+	var err error
 
-	storeEndpoint := client.apiUrl + "/api/v0/storage/add_file"
-	request, err := http.NewRequest("POST", storeEndpoint, body)
+	if seeker, ok := reader.(io.Seeker); ok {
+		var start int64
+		start, err = seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return StoreIPFSFileResponse{}, err
+		}
+
+		response, err = client.retry(ctx, func() (*http.Response, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, &readerError{err}
+			}
+
+			return client.streamMultipart(send, write)
+		})
+	} else {
+		response, err = client.streamMultipart(send, write)
+	}
 	if err != nil {
-		return Message{}, "", err
+		return StoreIPFSFileResponse{}, err
 	}
 
-	request.Header.Add("Content-Type", writer.FormDataContentType())
-	request.Header.Add("Accept", "application/json")
+	resultBody, err := readResponse(response)
+	if err != nil {
+		return StoreIPFSFileResponse{}, err
+	}
+
+	var uploaded StoreIPFSFileResponse
+	if err := json.Unmarshal(resultBody, &uploaded); err != nil {
+		return StoreIPFSFileResponse{}, err
+	}
+
+	return uploaded, nil
+}
+
+// streamMultipart sends the multipart body produced by write through a pipe.
+// Errors of write are returned as they are, after the request is aborted.
+func (client *TwentySixClient) streamMultipart(send func(body io.Reader, contentType string) (*http.Response, error), write func(writer *multipart.Writer) error) (*http.Response, error) {
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
 
-	response, err := client.http.Do(request)
+	done := make(chan error, 1)
+	go func() {
+		err := write(writer)
+		pipeWriter.CloseWithError(err)
+		done <- err
+	}()
+
+	response, err := send(pipeReader, writer.FormDataContentType())
 	if err != nil {
-		return Message{}, "", err
+		pipeReader.CloseWithError(err)
+	}
+
+	if writeErr := <-done; writeErr != nil {
+		if response != nil {
+			response.Body.Close()
+		}
+		return nil, writeErr
 	}
 
-	resultBody, err := io.ReadAll(response.Body)
+	return response, err
+}
+
+// writeStoreBody writes the file part then the metadata part, holding the
+// STORE message of the file, of a storage upload. The message is signed
+// once the file is hashed, unless message was already signed by a previous
+// attempt.
+func (client *TwentySixClient) writeStoreBody(ctx context.Context, writer *multipart.Writer, name string, reader io.Reader, size int64, message Message) (Message, error) {
+	hash := sha256.New()
+	if err := client.writeFilePart(writer, name, reader, size, hash); err != nil {
+		return Message{}, err
+	}
+
+	if message.Signature == "" {
+		now := float64(time.Now().UnixMilli()) / 1000
+
+		var err error
+		message, err = PrepareMessageWithContext(ctx, client.signer, client.channel, StoreMessageType, StoreMessageContent{
+			Address:  client.Address(),
+			Time:     now,
+			ItemHash: hex.EncodeToString(hash.Sum(nil)),
+			ItemType: StorageMessageItem,
+		}, now)
+		if err != nil {
+			return Message{}, err
+		}
+	}
+
+	metadata, err := json.Marshal(BroadcastRequest{
+		Message: message,
+		Sync:    client.sync,
+	})
 	if err != nil {
-		return Message{}, "", err
+		return Message{}, err
 	}
 
-	var storeFileResponse StoreIPFSFileResponse
-	if err := json.Unmarshal(resultBody, &storeFileResponse); err != nil {
-		return Message{}, "", err
+	if err := writer.WriteField("metadata", string(metadata)); err != nil {
+		return Message{}, err
 	}
 
-	defer response.Body.Close()
+	return message, writer.Close()
+}
+
+// writeFilePart copies the file to its multipart part and to hasher,
+// reporting progress, and checks its size. Errors reading the file are
+// returned as a *readerError.
+func (client *TwentySixClient) writeFilePart(writer *multipart.Writer, name string, reader io.Reader, size int64, hasher io.Writer) error {
+	filepart, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		return err
+	}
 
-	time.Sleep(5 * time.Second)
+	progress := &progressWriter{writer: filepart, total: size, progress: client.progress}
 
-	createdMessage, err := client.GetStoreMessageByItemHash(storeFileResponse.Hash)
+	written, err := io.Copy(io.MultiWriter(progress, hasher), &errorReader{reader})
 	if err != nil {
-		return Message{}, "", err
+		return err
+	}
+
+	if size >= 0 && written != size {
+		return &readerError{fmt.Errorf("read %d bytes from %s, expected %d", written, name, size)}
+	}
+
+	return nil
+}
+
+// readerError is an error reading the data of a streamed request. It comes
+// from the caller rather than the node, so the request is neither failed
+// over nor retried.
+type readerError struct {
+	err error
+}
+
+func (e *readerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *readerError) Unwrap() error {
+	return e.err
+}
+
+// errorReader wraps the errors of reader into a *readerError.
+type errorReader struct {
+	reader io.Reader
+}
+
+func (reader *errorReader) Read(data []byte) (int, error) {
+	n, err := reader.reader.Read(data)
+	if err != nil && err != io.EOF {
+		err = &readerError{err}
+	}
+
+	return n, err
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (writer *progressWriter) Write(data []byte) (int, error) {
+	n, err := writer.writer.Write(data)
+
+	writer.sent += int64(n)
+	if writer.progress != nil {
+		writer.progress(writer.sent, writer.total)
 	}
 
-	return createdMessage, storeFileResponse.Hash, nil
+	return n, err
 }
 
 func (client *TwentySixClient) GetStoreMessages(size uint64, page uint64) ([]Message, uint64, error) {
-	return client.GetMessages(size, page, []string{}, []string{client.account.Address}, []string{client.channel}, []MessageType{StoreMessageType})
+	return client.GetStoreMessagesWithContext(context.Background(), size, page)
+}
+
+func (client *TwentySixClient) GetStoreMessagesWithContext(ctx context.Context, size uint64, page uint64) ([]Message, uint64, error) {
+	return client.GetMessagesWithContext(ctx, size, page, nil, []string{client.Address()}, []string{client.channel}, []MessageType{StoreMessageType})
 }
 
 func (client *TwentySixClient) GetStoreMessageByItemHash(hash string) (Message, error) {
-	var page uint64 = 1
-	var parsingEnded = false
+	return client.GetStoreMessageByItemHashWithContext(context.Background(), hash)
+}
 
-	for !parsingEnded {
-		volumes, remainingItems, err := client.GetStoreMessages(50, page)
-		if err != nil {
-			return Message{}, err
-		}
+// GetStoreMessageByItemHashWithContext returns the latest processed STORE
+// message of the client for the file hash, or for the message item hash.
+func (client *TwentySixClient) GetStoreMessageByItemHashWithContext(ctx context.Context, hash string) (Message, error) {
+	return client.findOwnMessage(ctx, StoreMessageType, hash)
+}
 
-		for i := 0; i < len(volumes); i++ {
-			var itemContent StoreMessageContent
-			json.Unmarshal([]byte(volumes[i].ItemContent), &itemContent)
+// WaitStoreMessage polls every interval until the node processed a STORE
+// message of the client for the file hash, and returns it. Use
+// WaitStoreMessageWithContext to bound the wait: a deadline exceeded is
+// reported as ErrTimeout.
+func (client *TwentySixClient) WaitStoreMessage(hash string, interval time.Duration) (Message, error) {
+	return client.WaitStoreMessageWithContext(context.Background(), hash, interval)
+}
 
-			if itemContent.ItemHash == hash {
-				return volumes[i], nil
-			}
+func (client *TwentySixClient) WaitStoreMessageWithContext(ctx context.Context, hash string, interval time.Duration) (Message, error) {
+	for {
+		message, err := client.findOwnMessage(ctx, StoreMessageType, hash, ProcessedMessageStatus)
+		if err != nil && !errors.Is(err, ErrMessageNotFound) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return Message{}, fmt.Errorf("store message %s: %w: %w", hash, ErrTimeout, err)
+		}
+		if !errors.Is(err, ErrMessageNotFound) {
+			return message, err
 		}
 
-		if remainingItems > 0 {
-			page += 1
-		} else {
-			parsingEnded = true
+		if err := sleepContext(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return Message{}, fmt.Errorf("store message %s: %w", hash, ErrTimeout)
+			}
+			return Message{}, err
 		}
 	}
-
-	return Message{}, errors.New("store message not found")
 }
@@ -0,0 +1,262 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestStorageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var fileHash string
+		var request BroadcastRequest
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			switch part.FormName() {
+			case "file":
+				hash := sha256.New()
+				io.Copy(hash, part)
+				fileHash = hex.EncodeToString(hash.Sum(nil))
+			case "metadata":
+				json.NewDecoder(part).Decode(&request)
+			}
+		}
+
+		content, err := DecodeContent[StoreMessageContent](request.Message)
+		if err != nil || content.ItemHash != fileHash || request.Message.Verify() != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		json.NewEncoder(w).Encode(StoreIPFSFileResponse{Hash: fileHash, Status: SucceedMessageStatus})
+	})
+}
+
+func TestStoreReader(t *testing.T) {
+
+	client, _ := newTestClient(t, newTestStorageHandler())
+
+	data := bytes.Repeat([]byte("0123456789"), 100000)
+	expected := sha256.Sum256(data)
+
+	var sent, total int64
+	client = client.OnProgress(func(s int64, t int64) {
+		sent, total = s, t
+	})
+
+	message, hash, err := client.StoreReader("data.bin", bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf(`StoreReader failed: %v`, err)
+	}
+
+	if hash != hex.EncodeToString(expected[:]) || message.Type != StoreMessageType {
+		t.Fatalf(`Unexpected store result %s: %+v`, hash, message)
+	}
+
+	if sent != int64(len(data)) || total != int64(len(data)) {
+		t.Fatalf(`Unexpected progress %d/%d`, sent, total)
+	}
+
+	// A stream shorter than announced must not be stored.
+	if _, _, err := client.StoreReader("data.bin", bytes.NewReader(data[:10]), int64(len(data))); err == nil {
+		t.Fatalf(`Expected an error for a truncated stream`)
+	}
+}
+
+func TestStoreReaderRetry(t *testing.T) {
+
+	var attempts atomic.Int32
+	var metadata []string
+	storage := newTestStorageHandler()
+
+	policy := DefaultRetryPolicy
+	policy.InitialBackoff = time.Millisecond
+
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/storage/add_file" {
+			w.Write([]byte(`{"version":"test"}`))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		form := r.Clone(r.Context())
+		form.Body = io.NopCloser(bytes.NewReader(body))
+		metadata = append(metadata, form.FormValue("metadata"))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		storage.ServeHTTP(w, r)
+	}), WithRetryPolicy(policy))
+
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	reader := bytes.NewReader(append([]byte("header"), data...))
+	reader.Seek(int64(len("header")), io.SeekStart)
+
+	if _, _, err := client.StoreReader("data.bin", reader, int64(len(data))); err != nil {
+		t.Fatalf(`StoreReader was not retried: %v`, err)
+	}
+
+	if attempts.Load() != 2 || metadata[0] != metadata[1] {
+		t.Fatalf(`Expected the same message sent twice, got %d attempts`, attempts.Load())
+	}
+
+	// A reader that cannot seek is sent once.
+	attempts.Store(0)
+	if _, _, err := client.StoreReader("data.bin", io.MultiReader(bytes.NewReader(data)), int64(len(data))); err == nil || attempts.Load() != 1 {
+		t.Fatalf(`Expected a single failed attempt, got %d: %v`, attempts.Load(), err)
+	}
+}
+
+func TestStoreReaderIpfs(t *testing.T) {
+
+	returned := "bafybeicg2rebjoofv4kbyovkw7af3rpiitvnl6i7ckcywaq6xjcxnc2mby"
+	var broadcast BroadcastRequest
+
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/ipfs/add_file":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			io.Copy(io.Discard, file)
+
+			json.NewEncoder(w).Encode(StoreIPFSFileResponse{Hash: returned, Status: SucceedMessageStatus})
+		case "/api/v0/messages":
+			json.NewDecoder(r.Body).Decode(&broadcast)
+			w.Write([]byte(`{"publication_status":{"status":"success","failed":[]},"message_status":"pending"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}), WithStoreItemType(IpfsMessageItem))
+
+	message, cid, err := client.StoreReader("hello.txt", bytes.NewReader([]byte("hello world\n")), -1)
+	if err != nil {
+		t.Fatalf(`StoreReader failed: %v`, err)
+	}
+
+	content, err := DecodeContent[StoreMessageContent](broadcast.Message)
+	if err != nil || cid != "QmT78zSuBmuS4z925WZfrqQ1qHaJ56DQaTfyMUF7F8ff5o" || content.ItemHash != cid || content.ItemType != IpfsMessageItem || message.ItemHash != broadcast.Message.ItemHash {
+		t.Fatalf(`Unexpected STORE message %+v: %v`, broadcast.Message, err)
+	}
+
+	// A node adding files as CIDv1 builds a DAG with raw leaves.
+	returned = "bafkreifjjcie6lypi6ny7amxnfftagclbuxndqonfipmb64f2km2devei4"
+	if _, cid, err := client.StoreReader("hello.txt", bytes.NewReader([]byte("hello world\n")), -1); err != nil || cid != returned {
+		t.Fatalf(`Unexpected CIDv1 %s: %v`, cid, err)
+	}
+
+	// The node must not be trusted with the CID of the file.
+	returned = "QmbFMke1KXqnYyBBWxB74N4c5SBnJMVAiMNRcGu6x1AwQH"
+	if _, _, err := client.StoreReader("hello.txt", bytes.NewReader([]byte("hello world\n")), -1); !errors.Is(err, ErrItemHashMismatch) {
+		t.Fatalf(`Expected ErrItemHashMismatch, got %v`, err)
+	}
+}
+
+func TestStoreFileReturnsSignedMessage(t *testing.T) {
+
+	client, _ := newTestClient(t, newTestStorageHandler())
+
+	filePath := filepath.Join(t.TempDir(), "data.txt")
+	if err := os.WriteFile(filePath, []byte("hello world\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	message, hash, err := client.StoreFile(filePath)
+	if err != nil {
+		t.Fatalf(`StoreFile failed: %v`, err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Fatalf(`StoreFile should not wait for the message to be processed`)
+	}
+
+	content, err := DecodeContent[StoreMessageContent](message)
+	if err != nil || content.ItemHash != hash || message.Verify() != nil {
+		t.Fatalf(`Unexpected STORE message %+v: %v`, message, err)
+	}
+}
+
+func TestWaitStoreMessage(t *testing.T) {
+
+	acc := newTestAccount(t)
+
+	hash := "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447"
+	message, err := PrepareMessage(acc, "TEST", StoreMessageType, StoreMessageContent{Address: acc.GetAddress(), ItemHash: hash, ItemType: StorageMessageItem}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var polls atomic.Int32
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("contentHashes") != hash || query.Get("msgStatuses") != "processed" || query.Get("addresses") != acc.GetAddress() {
+			json.NewEncoder(w).Encode(GetMessageResponse{Messages: []Message{}})
+			return
+		}
+
+		// The message is processed on the third poll.
+		response := GetMessageResponse{Messages: []Message{}, PaginationPage: 1, PaginationPerPage: DefaultPageSize}
+		if polls.Add(1) >= 3 {
+			response.Messages = []Message{message}
+			response.PaginationTotal = 1
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}), WithChannel("TEST"))
+
+	if _, err := client.GetStoreMessageByItemHash(hash); !errors.Is(err, ErrMessageNotFound) {
+		t.Fatalf(`Expected ErrMessageNotFound, got %v`, err)
+	}
+
+	found, err := client.WaitStoreMessage(hash, 10*time.Millisecond)
+	if err != nil || found.ItemHash != message.ItemHash {
+		t.Fatalf(`Unexpected message %+v: %v`, found, err)
+	}
+
+	// The deadline is exceeded while the node is queried.
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	if _, err := client.WaitStoreMessageWithContext(ctx, "unknown", 10*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf(`Expected ErrTimeout, got %v`, err)
+	}
+
+	// The deadline is exceeded while waiting for the next poll.
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if _, err := client.WaitStoreMessageWithContext(ctx, "unknown", time.Hour); !errors.Is(err, ErrTimeout) {
+		t.Fatalf(`Expected ErrTimeout, got %v`, err)
+	}
+}
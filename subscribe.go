@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// minReconnectDelay avoids reconnecting in a tight loop when the retry policy
+// has no backoff.
+const minReconnectDelay = 100 * time.Millisecond
+
+// Subscribe streams the messages matching the filters, the same ones
+// GetMessages accepts, from the node messages websocket. The connection is
+// re-established when it drops, resuming from the time of the last message
+// received, and messages already delivered are not sent twice. The
+// subscription lasts as long as the program: use SubscribeWithContext to
+// stop it, the returned channel is then closed once ctx is done.
+func (client *TwentySixClient) Subscribe(hashes []string, addresses []string, channels []string, msgTypes []MessageType) (<-chan Message, error) {
+	return client.SubscribeWithContext(context.Background(), hashes, addresses, channels, msgTypes)
+}
+
+func (client *TwentySixClient) SubscribeWithContext(ctx context.Context, hashes []string, addresses []string, channels []string, msgTypes []MessageType) (<-chan Message, error) {
+	params := messageQuery(hashes, addresses, channels, msgTypes).values()
+
+	conn, err := client.dialMessages(ctx, params, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan Message)
+	go client.runSubscription(ctx, conn, params, messages)
+
+	return messages, nil
+}
+
+func (client *TwentySixClient) runSubscription(ctx context.Context, conn *websocket.Conn, params url.Values, messages chan<- Message) {
+	defer close(messages)
+
+	var lastTime float64
+	seen := map[string]bool{}
+
+	for {
+		stop := context.AfterFunc(ctx, func() {
+			conn.Close()
+		})
+
+		for {
+			var message Message
+			if err := conn.ReadJSON(&message); err != nil {
+				break
+			}
+
+			if message.ItemHash == "" || message.Time < lastTime || seen[message.ItemHash] {
+				continue
+			}
+
+			if message.Time > lastTime {
+				lastTime = message.Time
+				seen = map[string]bool{}
+			}
+			seen[message.ItemHash] = true
+
+			message = client.lazyContent(message)
+
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+			}
+		}
+
+		stop()
+		conn.Close()
+
+		conn = client.reconnectMessages(ctx, params, lastTime)
+		if conn == nil {
+			return
+		}
+	}
+}
+
+// reconnectMessages dials the websocket again until it succeeds, waiting
+// between attempts according to the retry policy. It returns nil once ctx is
+// done.
+func (client *TwentySixClient) reconnectMessages(ctx context.Context, params url.Values, since float64) *websocket.Conn {
+	for attempt := 1; ; attempt++ {
+		delay := client.retryPolicy.backoff(attempt, nil)
+		if delay < minReconnectDelay {
+			delay = minReconnectDelay
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil
+		}
+
+		conn, err := client.dialMessages(ctx, params, since)
+		if err == nil {
+			return conn
+		}
+
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// dialMessages connects to the messages websocket of the preferred node that
+// accepts the connection. Messages sent since the given time are replayed
+// first.
+func (client *TwentySixClient) dialMessages(ctx context.Context, params url.Values, since float64) (*websocket.Conn, error) {
+	if client.nodes.stale() {
+		client.CheckNodes(ctx)
+	}
+
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+
+	if since > 0 {
+		query.Set("startDate", fmt.Sprint(since))
+	}
+
+	header := http.Header{}
+	if client.userAgent != "" {
+		header.Set("User-Agent", client.userAgent)
+	}
+
+	dialer := *websocket.DefaultDialer
+	if transport, ok := client.http.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+
+	err := errors.New("no api node configured")
+	for _, nodeUrl := range client.nodes.ordered() {
+		endpoint := websocketUrl(nodeUrl) + "/api/ws0/messages?" + query.Encode()
+
+		var conn *websocket.Conn
+		var response *http.Response
+		conn, response, err = dialer.DialContext(ctx, endpoint, header)
+		if response != nil && response.Body != nil {
+			response.Body.Close()
+		}
+
+		if err == nil {
+			return conn, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		client.nodes.update(nodeUrl, false, 0, false)
+	}
+
+	return nil, err
+}
+
+func websocketUrl(nodeUrl string) string {
+	switch {
+	case strings.HasPrefix(nodeUrl, "https://"):
+		return "wss://" + strings.TrimPrefix(nodeUrl, "https://")
+	case strings.HasPrefix(nodeUrl, "http://"):
+		return "ws://" + strings.TrimPrefix(nodeUrl, "http://")
+	}
+
+	return nodeUrl
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestSubscribeResumesAfterDisconnect(t *testing.T) {
+
+	upgrader := websocket.Upgrader{}
+	startDates := make(chan string, 2)
+	connections := 0
+
+	client, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/ws0/messages" || r.URL.Query().Get("channels") != "TEST" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		connections++
+		startDates <- r.URL.Query().Get("startDate")
+
+		if connections == 1 {
+			conn.WriteJSON(Message{ItemHash: "first", Time: 1})
+			conn.WriteJSON(Message{ItemHash: "second", Time: 2})
+			return
+		}
+
+		conn.WriteJSON(Message{ItemHash: "second", Time: 2})
+		conn.WriteJSON(Message{ItemHash: "third", Time: 3})
+		conn.ReadMessage()
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages, err := client.SubscribeWithContext(ctx, nil, nil, []string{"TEST"}, nil)
+	if err != nil {
+		t.Fatalf(`Subscribe failed: %v`, err)
+	}
+
+	for _, expected := range []string{"first", "second", "third"} {
+		select {
+		case message := <-messages:
+			if message.ItemHash != expected {
+				t.Fatalf(`Expected message %s, got %s`, expected, message.ItemHash)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf(`Timeout waiting for message %s`, expected)
+		}
+	}
+
+	if <-startDates != "" || <-startDates != "2" {
+		t.Fatalf(`Subscription did not resume from the last message time`)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-messages:
+		if ok {
+			t.Fatalf(`Unexpected message after cancellation`)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf(`Channel not closed after cancellation`)
+	}
+}
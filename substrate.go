@@ -0,0 +1,219 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/crypto/blake2b"
+)
+
+// SubstrateSS58Format is the generic substrate address prefix, used by the
+// network for DOT senders.
+const SubstrateSS58Format uint16 = 42
+
+// substrateSigningContext is the sr25519 signing context used by substrate.
+var substrateSigningContext = []byte("substrate")
+
+type SubstrateAccount struct {
+	PrivateKey *schnorrkel.SecretKey
+	PublicKey  *schnorrkel.PublicKey
+	Address    string
+}
+
+type substrateSignature struct {
+	Curve string `json:"curve"`
+	Data  string `json:"data"`
+}
+
+// NewSubstrateAccountFromSeed accepts the hex encoded 32 bytes secret seed
+// printed by subkey.
+func NewSubstrateAccountFromSeed(seed string) (SubstrateAccount, error) {
+	seedBytes, err := hexutil.Decode(seed)
+	if err != nil {
+		return SubstrateAccount{}, errors.New("error casting seed to bytes")
+	}
+
+	if len(seedBytes) != schnorrkel.MiniSecretKeySize {
+		return SubstrateAccount{}, errors.New("invalid substrate seed length")
+	}
+
+	var raw [schnorrkel.MiniSecretKeySize]byte
+	copy(raw[:], seedBytes)
+
+	miniSecretKey, err := schnorrkel.NewMiniSecretKeyFromRaw(raw)
+	if err != nil {
+		return SubstrateAccount{}, err
+	}
+
+	return newSubstrateAccount(miniSecretKey)
+}
+
+// NewSubstrateAccountFromMnemonic derives the root sr25519 key of a mnemonic
+// the way substrate wallets do (substrate-bip39, without derivation path).
+func NewSubstrateAccountFromMnemonic(mnemonic string, password string) (SubstrateAccount, error) {
+	miniSecretKey, err := schnorrkel.MiniSecretKeyFromMnemonic(mnemonic, password)
+	if err != nil {
+		return SubstrateAccount{}, err
+	}
+
+	return newSubstrateAccount(miniSecretKey)
+}
+
+func newSubstrateAccount(miniSecretKey *schnorrkel.MiniSecretKey) (SubstrateAccount, error) {
+	secretKey := miniSecretKey.ExpandEd25519()
+
+	publicKey, err := secretKey.Public()
+	if err != nil {
+		return SubstrateAccount{}, err
+	}
+
+	encodedPublicKey := publicKey.Encode()
+
+	return SubstrateAccount{
+		PrivateKey: secretKey,
+		PublicKey:  publicKey,
+		Address:    SS58Encode(encodedPublicKey[:], SubstrateSS58Format),
+	}, nil
+}
+
+func (account SubstrateAccount) GetAddress() string {
+	return account.Address
+}
+
+func (account SubstrateAccount) GetChain() MessageChain {
+	return PolkadotChain
+}
+
+// Sign returns the sr25519 signature of the payload wrapped in the
+// {curve, data} JSON document expected for DOT messages.
+func (account SubstrateAccount) Sign(payload []byte) (string, error) {
+	if account.PrivateKey == nil {
+		return "", errors.New("account has no private key")
+	}
+
+	signature, err := account.PrivateKey.Sign(schnorrkel.NewSigningContext(substrateSigningContext, payload))
+	if err != nil {
+		return "", err
+	}
+
+	encodedSignature := signature.Encode()
+
+	result, err := json.Marshal(substrateSignature{
+		Curve: "sr25519",
+		Data:  hexutil.Encode(encodedSignature[:]),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(result), nil
+}
+
+// SS58Encode encodes a public key as a substrate address with the given
+// network prefix.
+func SS58Encode(publicKey []byte, format uint16) string {
+	var data []byte
+	if format < 64 {
+		data = []byte{byte(format)}
+	} else {
+		data = []byte{
+			byte((format&0x00fc)>>2) | 0x40,
+			byte(format>>8) | byte((format&0x0003)<<6),
+		}
+	}
+
+	data = append(data, publicKey...)
+	checksum := ss58Checksum(data)
+
+	return base58.Encode(append(data, checksum[:2]...))
+}
+
+// SS58Decode returns the public key and network prefix of a substrate
+// address after checking its checksum.
+func SS58Decode(address string) ([]byte, uint16, error) {
+	data := base58.Decode(address)
+	if len(data) < 3 {
+		return nil, 0, errors.New("invalid ss58 address")
+	}
+
+	var format uint16
+	var prefixLength int
+	if data[0] < 64 {
+		format = uint16(data[0])
+		prefixLength = 1
+	} else if data[0] < 128 {
+		format = uint16(data[0]&0x3f)<<2 | uint16(data[1]>>6) | uint16(data[1]&0x3f)<<8
+		prefixLength = 2
+	} else {
+		return nil, 0, errors.New("invalid ss58 address prefix")
+	}
+
+	if len(data) != prefixLength+32+2 {
+		return nil, 0, errors.New("invalid ss58 address length")
+	}
+
+	checksum := ss58Checksum(data[:len(data)-2])
+	if checksum[0] != data[len(data)-2] || checksum[1] != data[len(data)-1] {
+		return nil, 0, errors.New("invalid ss58 checksum")
+	}
+
+	return data[prefixLength : len(data)-2], format, nil
+}
+
+func ss58Checksum(data []byte) [64]byte {
+	return blake2b.Sum512(append([]byte("SS58PRE"), data...))
+}
+
+func verifySubstrateSignature(sender string, signature string, payload []byte) error {
+	var sig substrateSignature
+	if err := json.Unmarshal([]byte(signature), &sig); err != nil {
+		return err
+	}
+
+	if sig.Curve != "sr25519" {
+		return errors.New("unsupported curve: " + sig.Curve)
+	}
+
+	publicKeyBytes, _, err := SS58Decode(sender)
+	if err != nil {
+		return err
+	}
+
+	var rawPublicKey [schnorrkel.PublicKeySize]byte
+	copy(rawPublicKey[:], publicKeyBytes)
+
+	publicKey, err := schnorrkel.NewPublicKey(rawPublicKey)
+	if err != nil {
+		return err
+	}
+
+	sigBytes, err := hexutil.Decode(sig.Data)
+	if err != nil {
+		return err
+	}
+
+	if len(sigBytes) != schnorrkel.SignatureSize {
+		return errors.New("bad signature length")
+	}
+
+	var rawSignature [schnorrkel.SignatureSize]byte
+	copy(rawSignature[:], sigBytes)
+
+	var decoded schnorrkel.Signature
+	if err := decoded.Decode(rawSignature); err != nil {
+		return err
+	}
+
+	// Polkadot wallets sign extension payloads wrapped in <Bytes> tags.
+	for _, signed := range [][]byte{payload, []byte("<Bytes>" + string(payload) + "</Bytes>")} {
+		ok, err := publicKey.Verify(&decoded, schnorrkel.NewSigningContext(substrateSigningContext, signed))
+		if err == nil && ok {
+			return nil
+		}
+	}
+
+	return errors.New("sr25519 verification failed")
+}
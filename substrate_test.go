@@ -0,0 +1,62 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ChainSafe/go-schnorrkel"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Root account of the substrate development mnemonic, as printed by
+// `subkey inspect "bottom drive obey lake curtain smoke basket hold race lonely fit walk"`.
+const substrateDevMnemonic = "bottom drive obey lake curtain smoke basket hold race lonely fit walk"
+const substrateDevAddress = "5DfhGyQdFobKM8NsWvEeAKk5EQQgYe9AydgJ7rMB6E1EqRzV"
+
+func TestSubstrateAccountFromMnemonic(t *testing.T) {
+
+	acc, err := NewSubstrateAccountFromMnemonic(substrateDevMnemonic, "")
+	if err != nil {
+		t.Fatalf(`NewSubstrateAccountFromMnemonic failed to be instanciated: %v`, err)
+	}
+
+	publicKey := acc.PublicKey.Encode()
+	if hexutil.Encode(publicKey[:]) != "0x46ebddef8cd9bb167dc30878d7113b7e168e6f0646beffd77d69d39bad76b47a" {
+		t.Fatalf(`Bad public key generated`)
+	}
+	if acc.Address != substrateDevAddress {
+		t.Fatalf(`Bad address generated: %s`, acc.Address)
+	}
+}
+
+func TestPrepareSubstrateMessage(t *testing.T) {
+
+	acc, err := NewSubstrateAccountFromMnemonic(substrateDevMnemonic, "")
+	if err != nil {
+		t.Fatalf(`NewSubstrateAccountFromMnemonic failed to be instanciated: %v`, err)
+	}
+
+	message, err := PrepareMessage(acc, "TEST", PostMessageType, map[string]string{"Hello": "World"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.Chain != PolkadotChain || message.Sender != substrateDevAddress {
+		t.Fatalf(`Bad chain or sender in message`)
+	}
+
+	var signature substrateSignature
+	if err := json.Unmarshal([]byte(message.Signature), &signature); err != nil {
+		t.Fatalf(`Signature is not a JSON document: %v`, err)
+	}
+
+	sig, err := schnorrkel.NewSignatureFromHex(signature.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := acc.PublicKey.Verify(sig, schnorrkel.NewSigningContext(substrateSigningContext, message.GetVerificationPayload()))
+	if err != nil || !ok {
+		t.Fatalf(`Invalid sr25519 signature`)
+	}
+}
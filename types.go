@@ -1,5 +1,7 @@
 package client
 
+import "encoding/json"
+
 type MessageStatus string
 type MessageType string
 type MessageChain string
@@ -8,6 +10,9 @@ type VolumePersistence string
 type PaymentType string
 type CpuArchitecture string
 type CpuVendor string
+type KeystoreKDF string
+type MessageSortBy string
+type SortOrder int
 
 const (
 	AggregateMessageType MessageType = "AGGREGATE"
@@ -28,6 +33,8 @@ const (
 	ForgottenMessageStatus MessageStatus = "forgotten"
 
 	EthereumChain MessageChain = "ETH"
+	SolanaChain   MessageChain = "SOL"
+	PolkadotChain MessageChain = "DOT"
 
 	HostVolumePersistence  VolumePersistence = "host"
 	StoreVolumePersistence VolumePersistence = "store"
@@ -40,6 +47,15 @@ const (
 
 	AmdCpuVendor   CpuArchitecture = "AuthenticAMD"
 	IntelCpuVendor CpuArchitecture = "GenuineIntel"
+
+	ScryptKeystoreKDF KeystoreKDF = "scrypt"
+	Pbkdf2KeystoreKDF KeystoreKDF = "pbkdf2"
+
+	TimeMessageSort   MessageSortBy = "time"
+	TxTimeMessageSort MessageSortBy = "tx-time"
+
+	AscendingSortOrder  SortOrder = 1
+	DescendingSortOrder SortOrder = -1
 )
 
 type GetMessageResponse struct {
@@ -66,6 +82,26 @@ type AggregateMessageContent struct {
 	Content interface{} `json:"content"`
 }
 
+type GetAggregateResponse struct {
+	Address string                     `json:"address"`
+	Data    map[string]json.RawMessage `json:"data"`
+}
+
+// Authorization lets Address publish on behalf of the owner of the security
+// aggregate. Empty filters allow everything.
+type Authorization struct {
+	Address       string        `json:"address"`
+	Chain         MessageChain  `json:"chain,omitempty"`
+	Channels      []string      `json:"channels,omitempty"`
+	Types         []MessageType `json:"types,omitempty"`
+	PostTypes     []string      `json:"post_types,omitempty"`
+	AggregateKeys []string      `json:"aggregate_keys,omitempty"`
+}
+
+type SecurityAggregateContent struct {
+	Authorizations []Authorization `json:"authorizations"`
+}
+
 type PostMessageContent struct {
 	Type    string      `json:"type"`
 	Address string      `json:"address"`
@@ -219,6 +255,24 @@ type MessageResponse struct {
 		Failed []string      `json:"failed"`
 	} `json:"publication_status"`
 	Status MessageStatus `json:"message_status"`
+
+	// StatusCode is the HTTP status of the broadcast: 200 once the message
+	// is processed, 202 while it is still pending.
+	StatusCode int `json:"-"`
+}
+
+// MessageStatusResponse is returned by the node for a single message. Message
+// is set once processed, Messages holds the pending copies of the message and
+// ErrorCode and Details explain a rejection.
+type MessageStatusResponse struct {
+	ItemHash      string        `json:"item_hash"`
+	Status        MessageStatus `json:"status"`
+	ReceptionTime string        `json:"reception_time"`
+	Message       *Message      `json:"message,omitempty"`
+	Messages      []Message     `json:"messages,omitempty"`
+	ForgottenBy   []string      `json:"forgotten_by,omitempty"`
+	ErrorCode     int           `json:"error_code,omitempty"`
+	Details       interface{}   `json:"details,omitempty"`
 }
 
 type SchedulerAllocation struct {
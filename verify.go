@@ -0,0 +1,81 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrItemHashMismatch = errors.New("item hash does not match item content")
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrSenderMismatch   = errors.New("signature does not belong to sender")
+	ErrUnsupportedChain = errors.New("unsupported chain")
+)
+
+// VerificationError reports which check failed while verifying a message.
+// It unwraps to one of ErrItemHashMismatch, ErrInvalidSignature,
+// ErrSenderMismatch or ErrUnsupportedChain.
+type VerificationError struct {
+	ItemHash string
+	Chain    MessageChain
+	Check    error
+	Detail   string
+}
+
+func (e *VerificationError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("message %s (%s): %v", e.ItemHash, e.Chain, e.Check)
+	}
+
+	return fmt.Sprintf("message %s (%s): %v: %s", e.ItemHash, e.Chain, e.Check, e.Detail)
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Check
+}
+
+// Verify checks that the message is authentic: the item hash must match the
+// item content and the signature must have been made by the sender over the
+// verification payload.
+//
+// Storage and ipfs items reference content that is not part of the message,
+// their item hash is only checked once the content was fetched, see
+// TwentySixClient.ResolveContent.
+func (msg Message) Verify() error {
+	fail := func(check error, detail string) error {
+		return &VerificationError{ItemHash: msg.ItemHash, Chain: msg.Chain, Check: check, Detail: detail}
+	}
+
+	if msg.inline() || msg.ItemContent != "" {
+		if err := verifyItemHash(msg.ItemType, msg.ItemHash, []byte(msg.ItemContent)); err != nil {
+			return fail(ErrItemHashMismatch, "")
+		}
+	}
+
+	var verify func(sender string, signature string, payload []byte) error
+	switch msg.Chain {
+	case EthereumChain:
+		verify = verifyEthereumSignature
+	case SolanaChain:
+		verify = verifySolanaSignature
+	case PolkadotChain:
+		verify = verifySubstrateSignature
+	default:
+		return fail(ErrUnsupportedChain, "")
+	}
+
+	if err := verify(msg.Sender, msg.Signature, msg.GetVerificationPayload()); err != nil {
+		var verificationErr *VerificationError
+		if errors.As(err, &verificationErr) {
+			return fail(verificationErr.Check, verificationErr.Detail)
+		}
+
+		return fail(ErrInvalidSignature, err.Error())
+	}
+
+	return nil
+}
+
+func VerifyMessage(msg Message) error {
+	return msg.Verify()
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+func TestVerifyMessage(t *testing.T) {
+
+	ethAccount, err := NewTwentySixAccountFromPrivateKey("0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	solAccount, err := NewSolanaAccountFromPrivateKey(base58.Encode(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dotAccount, err := NewSubstrateAccountFromMnemonic(substrateDevMnemonic, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signer := range []Signer{ethAccount, solAccount, dotAccount} {
+		message, err := PrepareMessage(signer, "TEST", PostMessageType, map[string]string{"Hello": "World"}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := VerifyMessage(message); err != nil {
+			t.Fatalf(`Valid %s message failed verification: %v`, signer.GetChain(), err)
+		}
+
+		tampered := message
+		tampered.ItemContent = `{"Hello":"Mars"}`
+		if err := tampered.Verify(); !errors.Is(err, ErrItemHashMismatch) {
+			t.Fatalf(`Tampered %s content not detected: %v`, signer.GetChain(), err)
+		}
+
+		forged := message
+		forged.Type = AggregateMessageType
+		if err := forged.Verify(); err == nil {
+			t.Fatalf(`Forged %s message type not detected`, signer.GetChain())
+		}
+	}
+
+	message, err := PrepareMessage(ethAccount, "TEST", PostMessageType, map[string]string{"Hello": "World"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message.Sender = "0x0000000000000000000000000000000000000000"
+	if err := message.Verify(); !errors.Is(err, ErrSenderMismatch) && !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf(`Wrong sender not detected: %v`, err)
+	}
+
+	message.Chain = "UNKNOWN"
+	var verificationErr *VerificationError
+	if err := message.Verify(); !errors.As(err, &verificationErr) || verificationErr.Check != ErrUnsupportedChain {
+		t.Fatalf(`Unsupported chain not detected: %v`, err)
+	}
+}